@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"github.com/slimm609/checksec/v3/pkg/output"
 	"github.com/slimm609/checksec/v3/pkg/utils"
 
 	"github.com/spf13/cobra"
@@ -13,19 +14,19 @@ var dirCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	Example: `
   checksec dir /usr/bin/
-  checksec dir /usr/bin/ --recursive`,
+  checksec dir /usr/bin/ --recursive
+  checksec dir /usr/bin/ --jobs 4
+  checksec dir /usr/bin/ --policy policy.yaml`,
 	Run: func(cmd *cobra.Command, args []string) {
 		dir := args[0]
 		recursive, _ := cmd.Flags().GetBool("recursive")
-		utils.CheckDirExists(dir)
-		var Elements []interface{}
-		var ElementColors []interface{}
-		for _, file := range utils.GetAllFilesFromDir(dir, recursive) {
-			data, color := utils.RunFileChecks(file, libc)
-			Elements = append(Elements, data...)
-			ElementColors = append(ElementColors, color...)
+		jobs := getJobs(cmd)
+		policy := getPolicy(cmd)
+		files, err := utils.GetAllFilesFromDir(dir, recursive)
+		if err != nil {
+			output.Fatalf("Error: %v\n", err)
 		}
-		utils.FilePrinter(outputFormat, Elements, ElementColors, noBanner, noHeader)
+		scanFiles(files, jobs, policy)
 
 	},
 }
@@ -33,4 +34,6 @@ var dirCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(dirCmd)
 	dirCmd.Flags().BoolP("recursive", "r", false, "Enable recursive through the directories")
+	addJobsFlag(dirCmd)
+	addPolicyFlag(dirCmd)
 }
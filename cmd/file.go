@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"github.com/slimm609/checksec/v3/pkg/output"
 	"github.com/slimm609/checksec/v3/pkg/utils"
 
 	"github.com/spf13/cobra"
@@ -13,16 +14,26 @@ var fileCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	Example: `
   checksec file /usr/bin/ls
-  checksec file /usr/bin/ls --no-banner`,
+  checksec file /usr/bin/ls --no-banner
+  checksec file /usr/bin/ls --policy policy.yaml`,
 	Run: func(cmd *cobra.Command, args []string) {
 		file := args[0]
+		policy := getPolicy(cmd)
 
-		utils.CheckElfExists(file)
-		data, color := utils.RunFileChecks(file, libc)
-		utils.FilePrinter(outputFormat, data, color, noBanner, noHeader)
+		if err := utils.CheckElfExists(file); err != nil {
+			output.Fatalf("Error: %v\n", err)
+		}
+		scanner := newScanner()
+		report, err := scanner.ScanFile(file)
+		if err != nil {
+			output.Fatalf("Error: %v\n", err)
+		}
+		printReport(report)
+		exitOnViolations(policy.Evaluate(report))
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(fileCmd)
+	addPolicyFlag(fileCmd)
 }
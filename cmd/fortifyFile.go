@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/slimm609/checksec/v3/pkg/checksec"
+	"github.com/slimm609/checksec/v3/pkg/output"
 	"github.com/slimm609/checksec/v3/pkg/utils"
 
 	"github.com/spf13/cobra"
@@ -21,47 +21,15 @@ var fortifyFileCmd = &cobra.Command{
 		}
 		file := args[0]
 
-		utils.CheckElfExists(file)
-		binary := utils.GetBinary(file)
-		defer binary.Close()
-		fortify, err := checksec.Fortify(file, binary, libc)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error checking fortify: %v\n", err)
-			os.Exit(1)
+		if err := utils.CheckElfExists(file); err != nil {
+			output.Fatalf("Error: %v\n", err)
 		}
-		output := []interface{}{
-			map[string]interface{}{
-				"name": file,
-				"checks": map[string]interface{}{
-					"fortify_source": fortify.Output,
-					"fortified":      fortify.Fortified,
-					"fortifyable":    fortify.Fortifiable,
-					"noFortify":      fortify.NoFortify,
-					"libcSupport":    fortify.LibcSupport,
-					"numLibcFunc":    fortify.NumLibcFunc,
-					"numFileFunc":    fortify.NumFileFunc,
-				},
-			},
-		}
-		color := []interface{}{
-			map[string]interface{}{
-				"name": file,
-				"checks": map[string]interface{}{
-					"fortified":           fortify.Fortified,
-					"fortifiedColor":      "unset",
-					"noFortify":           fortify.NoFortify,
-					"fortifyable":         fortify.Fortifiable,
-					"fortifyableColor":    "unset",
-					"fortify_source":      fortify.Output,
-					"fortify_sourceColor": fortify.Color,
-					"libcSupport":         fortify.LibcSupport,
-					"libcSupportColor":    fortify.LibcSupportColor,
-					"numLibcFunc":         fortify.NumLibcFunc,
-					"numFileFunc":         fortify.NumFileFunc,
-				},
-			},
+		scanner := newScanner()
+		fortify, err := scanner.ScanFortify(file)
+		if err != nil {
+			output.Fatalf("Error checking fortify: %v\n", err)
 		}
-		utils.FortifyPrinter(outputFormat, output, color, noBanner, noHeader)
+		utils.FortifyPrinter(outputFormat, file, fortify, noBanner, noHeader)
 	},
 }
 
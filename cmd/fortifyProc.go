@@ -3,9 +3,10 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"path/filepath"
+	"strconv"
 
 	"github.com/slimm609/checksec/v3/pkg/checksec"
+	"github.com/slimm609/checksec/v3/pkg/output"
 	"github.com/slimm609/checksec/v3/pkg/utils"
 
 	"github.com/spf13/cobra"
@@ -20,61 +21,23 @@ var fortifyProcCmd = &cobra.Command{
 			fmt.Printf("Error: no process id provided")
 			os.Exit(1)
 		}
-		proc := args[0]
-
-		filePath := filepath.Join("/proc", proc, "exe")
-		file := filePath
-		if target, err := os.Readlink(filePath); err == nil {
-			file = target
-			if _, statErr := os.Stat(file); statErr != nil {
-				file = filePath
-			}
-		} else {
-			fmt.Fprintf(os.Stderr, "Error: Pid %s not found/no access\n", proc)
-			os.Exit(1)
+		pid, err := strconv.Atoi(args[0])
+		if err != nil {
+			output.Fatalf("Error: invalid pid %q\n", args[0])
 		}
-
-		utils.CheckElfExists(file)
-		binary := utils.GetBinary(file)
-		defer binary.Close()
-		fortify, err := checksec.Fortify(file, binary, libc)
+		file, err := checksec.ProcessExe(pid)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error checking fortify: %v\n", err)
-			os.Exit(1)
+			output.Fatalf("Error: %v\n", err)
 		}
-		output := []interface{}{
-			map[string]interface{}{
-				"name": file,
-				"checks": map[string]interface{}{
-					"fortify_source": fortify.Output,
-					"fortified":      fortify.Fortified,
-					"fortifyable":    fortify.Fortifiable,
-					"noFortify":      fortify.NoFortify,
-					"libcSupport":    fortify.LibcSupport,
-					"numLibcFunc":    fortify.NumLibcFunc,
-					"numFileFunc":    fortify.NumFileFunc,
-				},
-			},
+		if err := utils.CheckElfExists(file); err != nil {
+			output.Fatalf("Error: %v\n", err)
 		}
-		color := []interface{}{
-			map[string]interface{}{
-				"name": file,
-				"checks": map[string]interface{}{
-					"fortified":           fortify.Fortified,
-					"fortifiedColor":      "unset",
-					"noFortify":           fortify.NoFortify,
-					"fortifyable":         fortify.Fortifiable,
-					"fortifyableColor":    "unset",
-					"fortify_source":      fortify.Output,
-					"fortify_sourceColor": fortify.Color,
-					"libcSupport":         fortify.LibcSupport,
-					"libcSupportColor":    fortify.LibcSupportColor,
-					"numLibcFunc":         fortify.NumLibcFunc,
-					"numFileFunc":         fortify.NumFileFunc,
-				},
-			},
+		scanner := newScanner()
+		fortify, err := scanner.ScanFortify(file)
+		if err != nil {
+			output.Fatalf("Error checking fortify: %v\n", err)
 		}
-		utils.FortifyPrinter(outputFormat, output, color, noBanner, noHeader)
+		utils.FortifyPrinter(outputFormat, file, fortify, noBanner, noHeader)
 	},
 }
 
@@ -1,10 +1,7 @@
 package cmd
 
 import (
-	"fmt"
-	"os"
-	"strings"
-
+	"github.com/slimm609/checksec/v3/pkg/checksec"
 	"github.com/slimm609/checksec/v3/pkg/output"
 	"github.com/slimm609/checksec/v3/pkg/utils"
 	"github.com/spf13/cobra"
@@ -18,30 +15,13 @@ var kernelCmd = &cobra.Command{
 		var configFile string
 		if len(args) > 0 {
 			configFile = args[0]
-		} else if _, err := os.Stat("/proc/config.gz"); err == nil {
-			configFile = "/proc/config.gz"
-		} else {
-			osReleaseFile := "/proc/sys/kernel/osrelease"
-			_, err := os.Stat(osReleaseFile)
-			if err != nil {
-				output.Fatalf("Error: could not find kernel config: %v", err)
-			}
-			osReleaseVersion, err := os.ReadFile(osReleaseFile)
-			if err != nil {
-				output.Fatalf("Error: could not find kernel config: %v", err)
-			}
-			content := strings.ReplaceAll(string(osReleaseVersion), "\n", "")
-			configFile = fmt.Sprintf("%s-%s", "/boot/config", content)
-			_, err = os.Stat(configFile)
-			if err != nil {
-				output.Fatalf("Error: could not find kernel config: %v", err)
-			}
 		}
-
-		utils.CheckFileExists(configFile)
-
-		kernel, kernelColors := utils.ParseKernel(configFile)
-		utils.KernelPrinter(outputFormat, kernel, kernelColors, noBanner, noHeader)
+		scanner := checksec.Scanner{}
+		report, err := scanner.ScanKernel(configFile)
+		if err != nil {
+			output.Fatalf("Error: %v\n", err)
+		}
+		utils.KernelPrinter(outputFormat, report.Checks, noBanner, noHeader)
 	},
 }
 
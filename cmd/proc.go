@@ -1,12 +1,9 @@
 package cmd
 
 import (
-	"fmt"
-	"os"
+	"strconv"
 
-	"github.com/slimm609/checksec/v3/pkg/utils"
-
-	"path/filepath"
+	"github.com/slimm609/checksec/v3/pkg/output"
 
 	"github.com/spf13/cobra"
 )
@@ -17,23 +14,16 @@ var procCmd = &cobra.Command{
 	Short: "Check a file of a running process",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		proc := args[0]
-
-		file, err := os.Readlink(filepath.Join("/proc", proc, "exe"))
+		pid, err := strconv.Atoi(args[0])
 		if err != nil {
-			if os.IsNotExist(err) {
-				fmt.Printf("Error: Pid %s not found", proc)
-			} else if os.IsPermission(err) {
-				fmt.Printf("Error: Permission denied to access /proc/%s/exe", proc)
-			} else {
-				fmt.Printf("Error: %s", err.Error())
-			}
-			os.Exit(1)
+			output.Fatalf("Error: invalid pid %q\n", args[0])
 		}
-
-		utils.CheckElfExists(file)
-		data, color := utils.RunFileChecks(file, libc)
-		utils.FilePrinter(outputFormat, data, color, noBanner, noHeader)
+		scanner := newScanner()
+		report, err := scanner.ScanPID(pid)
+		if err != nil {
+			output.Fatalf("Error: %v\n", err)
+		}
+		printReport(report)
 	},
 }
 
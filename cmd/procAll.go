@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/slimm609/checksec/v3/pkg/checksec"
 	"github.com/slimm609/checksec/v3/pkg/utils"
 
 	"github.com/shirou/gopsutil/v3/process"
@@ -17,9 +19,10 @@ var procAllCmd = &cobra.Command{
 	Use:   "procAll",
 	Short: "Check all running processes",
 	Run: func(cmd *cobra.Command, args []string) {
+		jobs := getJobs(cmd)
+		policy := getPolicy(cmd)
 
-		var Elements []interface{}
-		var ElementColors []interface{}
+		var targets []procTarget
 		processes, _ := process.Processes()
 		for _, process := range processes {
 			proc := process.Pid
@@ -35,25 +38,14 @@ var procAllCmd = &cobra.Command{
 			if ppid, err := process.Ppid(); err == nil && ppid == 2 {
 				continue
 			}
-			filePath := filepath.Join("/proc", fmt.Sprint(proc), "exe")
-
 			// Skip kernel threads; they do not have an exe.
 			if isKthread(proc) {
 				continue
 			}
-
-			file := filePath
-			if target, err := os.Readlink(filePath); err == nil {
-				file = strings.Split(target, " ")[0]
-				if _, statErr := os.Stat(file); statErr != nil {
-					// Fall back to /proc/<pid>/exe when target is not accessible
-					file = filePath
-				}
-			} else {
-				// If we cannot read the link, try using the symlink path
-				file = filePath
+			file, err := checksec.ProcessExe(int(proc))
+			if err != nil {
+				continue
 			}
-
 			if _, err := os.Stat(file); err != nil {
 				// Cannot access exe (e.g., permission denied); skip this process
 				continue
@@ -61,16 +53,29 @@ var procAllCmd = &cobra.Command{
 			if !utils.CheckIfElf(file) {
 				continue
 			}
-			data, color := utils.RunFileChecks(file, libc)
-			Elements = append(Elements, data...)
-			ElementColors = append(ElementColors, color...)
+			targets = append(targets, procTarget{pid: proc, file: file})
 		}
-		utils.FilePrinter(outputFormat, Elements, ElementColors, noBanner, noHeader)
+
+		// Report in PID order regardless of which scan finishes first.
+		sort.Slice(targets, func(i, j int) bool { return targets[i].pid < targets[j].pid })
+		files := make([]string, len(targets))
+		for i, target := range targets {
+			files[i] = target.file
+		}
+		scanFiles(files, jobs, policy)
 	},
 }
 
+// procTarget is the executable of a running process.
+type procTarget struct {
+	pid  int32
+	file string
+}
+
 func init() {
 	rootCmd.AddCommand(procAllCmd)
+	addJobsFlag(procAllCmd)
+	addPolicyFlag(procAllCmd)
 }
 
 func isKthread(pid int32) bool {
@@ -3,9 +3,12 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"runtime"
 
 	"github.com/fatih/color"
+	"github.com/slimm609/checksec/v3/pkg/checksec"
 	"github.com/slimm609/checksec/v3/pkg/output"
+	"github.com/slimm609/checksec/v3/pkg/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -16,6 +19,9 @@ var (
 	noHeader     bool
 	noWarnings   bool
 	colorMode    string
+	verbose      bool
+	denyList     string
+	fortifyList  string
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -25,6 +31,106 @@ var rootCmd = &cobra.Command{
 	Long:  `A tool used to quickly survey mitigation technologies in use by processes on a Linux system.`,
 }
 
+// addJobsFlag adds the --jobs flag to commands that scan many files.
+func addJobsFlag(cmd *cobra.Command) {
+	cmd.Flags().IntP("jobs", "j", runtime.NumCPU(), "Number of files to scan concurrently")
+}
+
+// getJobs returns the validated value of the --jobs flag.
+func getJobs(cmd *cobra.Command) int {
+	jobs, _ := cmd.Flags().GetInt("jobs")
+	if jobs < 1 {
+		output.Fatalf("Error: invalid --jobs value %d (must be at least 1)\n", jobs)
+	}
+	return jobs
+}
+
+// addPolicyFlag adds the --policy flag to commands that scan files.
+func addPolicyFlag(cmd *cobra.Command) {
+	cmd.Flags().String("policy", "", "Policy file of required mitigations; violations are listed and exit non-zero")
+}
+
+// getPolicy loads the policy named by the --policy flag, or returns nil when
+// the flag is not set.
+func getPolicy(cmd *cobra.Command) *checksec.Policy {
+	path, _ := cmd.Flags().GetString("policy")
+	if path == "" {
+		return nil
+	}
+	policy, err := checksec.LoadPolicy(path)
+	if err != nil {
+		output.Fatalf("Error: %v\n", err)
+	}
+	return policy
+}
+
+// scanFiles scans files on up to jobs workers and prints each report as soon
+// as it and the reports before it are ready. Files that cannot be scanned
+// are reported on stderr and skipped. Reports that violate policy are listed
+// on stderr once the scan is done, and checksec exits non-zero.
+func scanFiles(files []string, jobs int, policy *checksec.Policy) {
+	scanner := newScanner()
+	scanner.Jobs = jobs
+	w := newFileWriter()
+	var violations []checksec.Violation
+	scanner.ScanFiles(files, func(_ string, report *checksec.FileReport, err error) {
+		if err != nil {
+			output.Warnf("Error: %v", err)
+			return
+		}
+		w.Write(report)
+		violations = append(violations, policy.Evaluate(report)...)
+	})
+	w.Close()
+	exitOnViolations(violations)
+}
+
+// newScanner returns a Scanner for the global flags.
+func newScanner() checksec.Scanner {
+	scanner := checksec.Scanner{Libc: libc}
+	if denyList != "" {
+		functions, err := checksec.LoadFunctionList(denyList)
+		if err != nil {
+			output.Fatalf("Error: %v\n", err)
+		}
+		scanner.DangerousFunctions = functions
+	}
+	if fortifyList != "" {
+		filter, err := checksec.LoadFortifyFilter(fortifyList)
+		if err != nil {
+			output.Fatalf("Error: %v\n", err)
+		}
+		scanner.FortifyFilter = filter
+	}
+	return scanner
+}
+
+// newFileWriter returns a FileWriter for the global output flags.
+func newFileWriter() *utils.FileWriter {
+	w := utils.NewFileWriter(outputFormat, noBanner, noHeader)
+	w.Verbose = verbose
+	return w
+}
+
+// printReport prints a single report with the global output flags.
+func printReport(report *checksec.FileReport) {
+	w := newFileWriter()
+	w.Write(report)
+	w.Close()
+}
+
+// exitOnViolations lists policy violations on stderr and exits non-zero if
+// there are any.
+func exitOnViolations(violations []checksec.Violation) {
+	if len(violations) == 0 {
+		return
+	}
+	for _, v := range violations {
+		fmt.Fprintf(os.Stderr, "Policy violation: %v\n", v)
+	}
+	output.Fatalf("Error: %d policy violation(s)\n", len(violations))
+}
+
 func SetVersionInfo(version, commit, date string) {
 	rootCmd.Version = fmt.Sprintf("%s (Built on %s from Git SHA %s)", version, date, commit)
 }
@@ -32,11 +138,14 @@ func SetVersionInfo(version, commit, date string) {
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "table", "Output format (table, xml, json or yaml)")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "table", "Output format (table, xml, json, ndjson, csv, tsv, sarif, junit or yaml)")
 	rootCmd.PersistentFlags().StringVarP(&libc, "libc", "l", "", "Set libc location (useful for FORTIFY check on offline embedded file-system)")
+	rootCmd.PersistentFlags().StringVar(&denyList, "deny-list", "", "File of dangerous function names, one per line, replacing the built-in list")
+	rootCmd.PersistentFlags().StringVar(&fortifyList, "fortify-list", "", "File of __*_chk functions to limit the FORTIFY check to, one per line; names prefixed with ! are excluded")
 	rootCmd.PersistentFlags().BoolVarP(&noBanner, "no-banner", "", false, "disable the banner")
 	rootCmd.PersistentFlags().BoolVarP(&noHeader, "no-headers", "", false, "disable the headers")
 	rootCmd.PersistentFlags().BoolVarP(&noWarnings, "no-warnings", "", false, "disable warnings")
+	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "list the details behind each check below the table row, such as text relocations")
 	rootCmd.PersistentFlags().StringVar(&colorMode, "color", "auto", "Color output mode (auto, always, never)")
 
 	cobra.OnInitialize(func() {
@@ -0,0 +1,301 @@
+package checksec
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Annobin notes, as described by the Watermark specification used by the
+// annobin GCC plugin. Each note records one compiler option for a range of
+// code; its name is "GA", a value type, the attribute and the value.
+const (
+	ntGNUBuildAttributeOpen = 0x100
+	ntGNUBuildAttributeFunc = 0x101
+
+	annobinNumeric = '*'
+	annobinString  = '$'
+	annobinTrue    = '+'
+	annobinFalse   = '!'
+
+	// annobinStackProt is the only numbered attribute this check reads;
+	// the others are named.
+	annobinStackProt = 2
+)
+
+// annobinSections are the sections annobin stores its notes in.
+var annobinSections = []string{".gnu.build.attributes", ".note.gnu.build-attributes"}
+
+// annobinAttr is a single decoded annobin note.
+type annobinAttr struct {
+	// name is the attribute name, or the attribute number for the numbered
+	// attributes.
+	name string
+	kind byte
+	num  uint64
+	str  string
+}
+
+// parseAnnobinNote decodes the name of an annobin note.
+func parseAnnobinNote(note Note) (annobinAttr, bool) {
+	if note.Type != ntGNUBuildAttributeOpen && note.Type != ntGNUBuildAttributeFunc {
+		return annobinAttr{}, false
+	}
+	if len(note.Name) < 4 || !strings.HasPrefix(note.Name, "GA") {
+		return annobinAttr{}, false
+	}
+	attr := annobinAttr{kind: note.Name[2]}
+	rest := note.Name[3:]
+	if rest[0] < ' ' {
+		attr.name, rest = strconv.Itoa(int(rest[0])), rest[1:]
+	} else {
+		end := strings.IndexByte(rest, 0)
+		if end < 0 {
+			end = len(rest)
+			attr.name, rest = rest, ""
+		} else {
+			attr.name, rest = rest[:end], rest[end+1:]
+		}
+	}
+
+	switch attr.kind {
+	case annobinNumeric:
+		// Little endian, in as few bytes as needed.
+		if len(rest) > 8 {
+			return annobinAttr{}, false
+		}
+		for i := len(rest) - 1; i >= 0; i-- {
+			attr.num = attr.num<<8 | uint64(rest[i])
+		}
+	case annobinString:
+		attr.str = strings.TrimRight(rest, "\x00")
+	case annobinTrue:
+		attr.num = 1
+	case annobinFalse:
+	default:
+		return annobinAttr{}, false
+	}
+	return attr, true
+}
+
+// AnnobinFlags is the machine value of the annobin check. Each field lists
+// the distinct values recorded for a compiler option, weakest first; an
+// option without notes is left empty.
+type AnnobinFlags struct {
+	// Fortify lists the -D_FORTIFY_SOURCE levels.
+	Fortify []int `json:"fortify,omitempty"`
+	// StackProtector lists the stack protector modes: "none", "explicit",
+	// "basic", "strong" or "all".
+	StackProtector []string `json:"stackProtector,omitempty"`
+	// StackClash lists whether -fstack-clash-protection was used.
+	StackClash []bool `json:"stackClash,omitempty"`
+	// CFProtection lists the -fcf-protection modes: "none", "branch",
+	// "return" or "full".
+	CFProtection []string `json:"cfProtection,omitempty"`
+	// GlibcxxAssertions lists whether _GLIBCXX_ASSERTIONS was defined.
+	GlibcxxAssertions []bool `json:"glibcxxAssertions,omitempty"`
+	// AutoVarInit lists the -ftrivial-auto-var-init modes:
+	// "uninitialized", "pattern" or "zero".
+	AutoVarInit []string `json:"autoVarInit,omitempty"`
+	// Mismatches describes where the notes disagree with the symbol
+	// heuristics of the canary and FORTIFY checks.
+	Mismatches []string `json:"mismatches,omitempty"`
+}
+
+// Mode names, indexed by the value annobin records, and their order from
+// weakest to strongest.
+var (
+	stackProtModes   = []string{"none", "basic", "all", "strong", "explicit"}
+	stackProtRank    = []string{"none", "explicit", "basic", "strong", "all"}
+	cfProtModes      = []string{"none", "branch", "return", "full"}
+	autoVarInitModes = []string{"uninitialized", "pattern", "zero"}
+)
+
+// annobinFlags collects the options recorded in notes. It returns false when
+// there are no annobin notes.
+func annobinFlags(notes []Note) (AnnobinFlags, bool) {
+	var flags AnnobinFlags
+	found := false
+	addMode := func(list *[]string, modes []string, v uint64) {
+		if v < uint64(len(modes)) && !slices.Contains(*list, modes[v]) {
+			*list = append(*list, modes[v])
+		}
+	}
+	addBool := func(list *[]bool, v uint64) {
+		if !slices.Contains(*list, v != 0) {
+			*list = append(*list, v != 0)
+		}
+	}
+
+	for _, note := range notes {
+		attr, ok := parseAnnobinNote(note)
+		if !ok {
+			continue
+		}
+		found = true
+		switch attr.name {
+		case "FORTIFY":
+			// Levels above 3 mean the level could not be determined,
+			// for example in LTO builds.
+			if attr.num <= 3 && !slices.Contains(flags.Fortify, int(attr.num)) {
+				flags.Fortify = append(flags.Fortify, int(attr.num))
+			}
+		case strconv.Itoa(annobinStackProt):
+			addMode(&flags.StackProtector, stackProtModes, attr.num)
+		case "stack_clash":
+			addBool(&flags.StackClash, attr.num)
+		case "cf_protection":
+			// Recorded as the GCC cf_protection level plus one; the
+			// upper bits mark -mmanual-endbr and friends.
+			if attr.num > 0 {
+				addMode(&flags.CFProtection, cfProtModes, (attr.num-1)&3)
+			}
+		case "GLIBCXX_ASSERTIONS":
+			addBool(&flags.GlibcxxAssertions, attr.num)
+		case "INIT", "auto_var_init":
+			addMode(&flags.AutoVarInit, autoVarInitModes, attr.num)
+		}
+	}
+
+	slices.Sort(flags.Fortify)
+	sortByRank(flags.StackProtector, stackProtRank)
+	sortByRank(flags.CFProtection, cfProtModes)
+	sortByRank(flags.AutoVarInit, autoVarInitModes)
+	sortBools(flags.StackClash)
+	sortBools(flags.GlibcxxAssertions)
+	return flags, found
+}
+
+func sortByRank(list, rank []string) {
+	slices.SortFunc(list, func(a, b string) int {
+		return slices.Index(rank, a) - slices.Index(rank, b)
+	})
+}
+
+func sortBools(list []bool) {
+	if len(list) == 2 {
+		list[0], list[1] = false, true
+	}
+}
+
+// weak returns the options whose weakest recorded value is not hardened.
+// _GLIBCXX_ASSERTIONS and -ftrivial-auto-var-init are reported but not
+// required, since they only apply to C++ or are still uncommon.
+func (f AnnobinFlags) weak() []string {
+	var weak []string
+	if len(f.Fortify) > 0 && f.Fortify[0] < 2 {
+		weak = append(weak, "FORTIFY")
+	}
+	if len(f.StackProtector) > 0 && slices.Index(stackProtRank, f.StackProtector[0]) < slices.Index(stackProtRank, "strong") {
+		weak = append(weak, "SSP")
+	}
+	if len(f.StackClash) > 0 && !f.StackClash[0] {
+		weak = append(weak, "clash")
+	}
+	if len(f.CFProtection) > 0 && f.CFProtection[0] != "full" {
+		weak = append(weak, "CF")
+	}
+	return weak
+}
+
+// String lists the recorded options, for example
+// "FORTIFY=2 SSP=strong clash=yes CF=full". Options with several values show
+// them all, weakest first.
+func (f AnnobinFlags) String() string {
+	var parts []string
+	add := func(name string, values []string) {
+		if len(values) > 0 {
+			parts = append(parts, name+"="+strings.Join(values, "/"))
+		}
+	}
+	yesNo := func(values []bool) []string {
+		var s []string
+		for _, v := range values {
+			s = append(s, map[bool]string{true: "yes", false: "no"}[v])
+		}
+		return s
+	}
+	var levels []string
+	for _, l := range f.Fortify {
+		levels = append(levels, strconv.Itoa(l))
+	}
+	add("FORTIFY", levels)
+	add("SSP", f.StackProtector)
+	add("clash", yesNo(f.StackClash))
+	add("CF", f.CFProtection)
+	add("assert", yesNo(f.GlibcxxAssertions))
+	add("init", f.AutoVarInit)
+	return strings.Join(parts, " ")
+}
+
+// annobinMismatches compares the recorded options with the symbols the canary
+// and FORTIFY checks rely on.
+func annobinMismatches(b *Binary, f AnnobinFlags) []string {
+	var mismatches []string
+	if len(f.StackProtector) > 0 {
+		protected := slices.ContainsFunc(f.StackProtector, func(m string) bool { return m != "none" })
+		canary := canaryMechanism(b) != ""
+		if protected && !canary {
+			mismatches = append(mismatches, "stack protector enabled but no canary symbol found")
+		} else if !protected && canary {
+			mismatches = append(mismatches, "canary symbol found but stack protector disabled")
+		}
+	}
+	if len(f.Fortify) > 0 {
+		fortified := b.HasSymbolFunc(isChkFunc)
+		if f.Fortify[len(f.Fortify)-1] > 0 && !fortified {
+			mismatches = append(mismatches, fmt.Sprintf("FORTIFY_SOURCE=%d but no fortified functions found", f.Fortify[len(f.Fortify)-1]))
+		} else if f.Fortify[len(f.Fortify)-1] == 0 && fortified {
+			mismatches = append(mismatches, "fortified functions found but FORTIFY_SOURCE disabled")
+		}
+	}
+	return mismatches
+}
+
+// annobinNotes returns the notes of the annobin sections of b.
+func annobinNotes(b *Binary) []Note {
+	var notes []Note
+	for _, name := range annobinSections {
+		section := b.File.Section(name)
+		if section == nil {
+			continue
+		}
+		data, err := b.SectionData(name)
+		if err != nil {
+			continue
+		}
+		notes = append(notes, parseNotes(data, b.File.ByteOrder, section.Addralign)...)
+	}
+	return notes
+}
+
+// annobinCheck reports the compiler options recorded in the annobin notes of
+// b.
+func annobinCheck(b *Binary) *Result {
+	flags, ok := annobinFlags(annobinNotes(b))
+	if !ok {
+		return &Result{Status: StatusNA, Output: "No Notes"}
+	}
+	flags.Mismatches = annobinMismatches(b, flags)
+
+	res := &Result{Status: StatusPass, Output: "Hardened", Value: flags}
+	if len(flags.weak()) > 0 {
+		res.Status = StatusPartial
+		res.Output = "Weak: " + strings.Join(flags.weak(), ",")
+	}
+	if len(flags.Mismatches) > 0 {
+		if res.Status == StatusPass {
+			res.Status = StatusUnknown
+		}
+		res.Output += " (mismatch)"
+	}
+	return res
+}
+
+// AnnobinFlagsOf returns the AnnobinFlags carried by an annobin result, or
+// the zero value if the binary has no annobin notes.
+func AnnobinFlagsOf(r Result) AnnobinFlags {
+	flags, _ := r.Value.(AnnobinFlags)
+	return flags
+}
@@ -0,0 +1,191 @@
+package checksec
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// annobinNote returns an open annobin note with the given name, as parseNotes
+// returns it.
+func annobinNote(name string) Note {
+	return Note{Name: name, Type: ntGNUBuildAttributeOpen}
+}
+
+func TestParseAnnobinNote(t *testing.T) {
+	tests := []struct {
+		name string
+		note Note
+		want annobinAttr
+		ok   bool
+	}{
+		{"named numeric", annobinNote("GA*FORTIFY\x00\x02"), annobinAttr{name: "FORTIFY", kind: annobinNumeric, num: 2}, true},
+		{"numeric zero", annobinNote("GA*FORTIFY\x00\x00"), annobinAttr{name: "FORTIFY", kind: annobinNumeric}, true},
+		{"multi-byte numeric", annobinNote("GA*cf_protection\x00\x08\x01"), annobinAttr{name: "cf_protection", kind: annobinNumeric, num: 0x108}, true},
+		{"numbered attribute", annobinNote("GA*\x02\x03"), annobinAttr{name: "2", kind: annobinNumeric, num: 3}, true},
+		{"bool true", annobinNote("GA+stack_clash"), annobinAttr{name: "stack_clash", kind: annobinTrue, num: 1}, true},
+		{"bool false", annobinNote("GA!GLIBCXX_ASSERTIONS"), annobinAttr{name: "GLIBCXX_ASSERTIONS", kind: annobinFalse}, true},
+		{"string", annobinNote("GA$\x05gcc 14.2.1"), annobinAttr{name: "5", kind: annobinString, str: "gcc 14.2.1"}, true},
+		{"func note", Note{Name: "GA+stack_clash", Type: ntGNUBuildAttributeFunc}, annobinAttr{name: "stack_clash", kind: annobinTrue, num: 1}, true},
+		{"other note type", Note{Name: "GA+stack_clash", Type: ntGNUPropertyType0}, annobinAttr{}, false},
+		{"not annobin", annobinNote("GNU"), annobinAttr{}, false},
+		{"unknown value type", annobinNote("GA?FORTIFY"), annobinAttr{}, false},
+		{"oversized numeric", annobinNote("GA*FORTIFY\x00123456789"), annobinAttr{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseAnnobinNote(tt.note)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("parseAnnobinNote(%q) = %+v, %v; want %+v, %v", tt.note.Name, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParseNotes_KeepsAnnobinValues(t *testing.T) {
+	bo := binary.LittleEndian
+	data := buildNote(bo, "GA*FORTIFY\x00\x00", ntGNUBuildAttributeOpen, nil, 4)
+	notes := parseNotes(data, bo, 4)
+	if len(notes) != 1 || notes[0].Name != "GA*FORTIFY\x00\x00" {
+		t.Fatalf("parseNotes = %q, want the value byte kept", notes)
+	}
+}
+
+func TestAnnobinFlags(t *testing.T) {
+	tests := []struct {
+		name   string
+		notes  []Note
+		want   AnnobinFlags
+		str    string
+		weak   []string
+		hasAny bool
+	}{
+		{
+			name:  "no notes",
+			notes: []Note{{Name: "GNU", Type: ntGNUPropertyType0}},
+		},
+		{
+			name: "hardened",
+			notes: []Note{
+				annobinNote("GA*FORTIFY\x00\x03"),
+				annobinNote("GA*\x02\x03"),
+				annobinNote("GA+stack_clash"),
+				annobinNote("GA*cf_protection\x00\x04"),
+				annobinNote("GA+GLIBCXX_ASSERTIONS"),
+				annobinNote("GA*INIT\x00\x02"),
+			},
+			want: AnnobinFlags{
+				Fortify:           []int{3},
+				StackProtector:    []string{"strong"},
+				StackClash:        []bool{true},
+				CFProtection:      []string{"full"},
+				GlibcxxAssertions: []bool{true},
+				AutoVarInit:       []string{"zero"},
+			},
+			str:    "FORTIFY=3 SSP=strong clash=yes CF=full assert=yes init=zero",
+			hasAny: true,
+		},
+		{
+			name: "mixed values sort weakest first",
+			notes: []Note{
+				annobinNote("GA*FORTIFY\x00\x02"),
+				annobinNote("GA*FORTIFY\x00\x00"),
+				annobinNote("GA*FORTIFY\x00\xff"),
+				annobinNote("GA*\x02\x02"),
+				annobinNote("GA*\x02\x04"),
+				annobinNote("GA*\x02\x02"),
+				annobinNote("GA+stack_clash"),
+				annobinNote("GA!stack_clash"),
+				annobinNote("GA*cf_protection\x00\x02"),
+				annobinNote("GA*cf_protection\x00\x08"),
+			},
+			want: AnnobinFlags{
+				Fortify:        []int{0, 2},
+				StackProtector: []string{"explicit", "all"},
+				StackClash:     []bool{false, true},
+				CFProtection:   []string{"branch", "full"},
+			},
+			str:    "FORTIFY=0/2 SSP=explicit/all clash=no/yes CF=branch/full",
+			weak:   []string{"FORTIFY", "SSP", "clash", "CF"},
+			hasAny: true,
+		},
+		{
+			name: "assertions and auto-var-init are not required",
+			notes: []Note{
+				annobinNote("GA!GLIBCXX_ASSERTIONS"),
+				annobinNote("GA*INIT\x00\x00"),
+			},
+			want: AnnobinFlags{
+				GlibcxxAssertions: []bool{false},
+				AutoVarInit:       []string{"uninitialized"},
+			},
+			str:    "assert=no init=uninitialized",
+			hasAny: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := annobinFlags(tt.notes)
+			if ok != tt.hasAny {
+				t.Fatalf("annobinFlags found = %v, want %v", ok, tt.hasAny)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("annobinFlags = %+v, want %+v", got, tt.want)
+			}
+			if s := got.String(); s != tt.str {
+				t.Errorf("String() = %q, want %q", s, tt.str)
+			}
+			if w := got.weak(); !reflect.DeepEqual(w, tt.weak) {
+				t.Errorf("weak() = %v, want %v", w, tt.weak)
+			}
+		})
+	}
+}
+
+func TestAnnobinMismatches(t *testing.T) {
+	b, err := OpenBinary(requireFixture(t, "none"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	tests := []struct {
+		name  string
+		flags AnnobinFlags
+		want  []string
+	}{
+		{"no options", AnnobinFlags{}, nil},
+		{"options off", AnnobinFlags{Fortify: []int{0}, StackProtector: []string{"none"}}, nil},
+		{
+			name:  "options on without symbols",
+			flags: AnnobinFlags{Fortify: []int{0, 2}, StackProtector: []string{"strong"}},
+			want: []string{
+				"stack protector enabled but no canary symbol found",
+				"FORTIFY_SOURCE=2 but no fortified functions found",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := annobinMismatches(b, tt.flags); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("annobinMismatches = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnnobinCheck_NoNotes(t *testing.T) {
+	b, err := OpenBinary(requireFixture(t, "all"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	res := annobinCheck(b)
+	if res.Status != StatusNA || res.Output != "No Notes" || res.Value != nil {
+		t.Errorf("annobinCheck = %+v, want N/A without a value", res)
+	}
+	if s := AnnobinFlagsOf(*res).String(); s != "" {
+		t.Errorf("flags of a binary without notes = %q, want empty", s)
+	}
+}
@@ -0,0 +1,353 @@
+package checksec
+
+import (
+	"debug/dwarf"
+	"debug/elf"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/slimm609/checksec/v3/pkg/output"
+)
+
+// Binary is an opened ELF file handed to every Check. The expensive parts of
+// the file (dynamic entries, symbol tables, notes and section data) are
+// decoded on first use and cached, so checks can share a single parse.
+// A Binary is safe for concurrent use by multiple checks.
+type Binary struct {
+	// Path is the path the binary was opened from.
+	Path string
+	// File is the parsed ELF file.
+	File *elf.File
+	// Libc is the libc used by the FORTIFY check; "" resolves it from the
+	// binary's dependencies.
+	Libc string
+	// DangerousFunctions is the deny list of the dangerous check; nil uses
+	// DefaultDangerousFunctions.
+	DangerousFunctions []string
+	// FortifyFilter adjusts the libc functions of the FORTIFY check; nil
+	// uses every __*_chk function the libc provides.
+	FortifyFilter *FortifyFilter
+
+	// raw is the underlying file, used for reads that debug/elf does not
+	// expose. It is opened from Path on demand when not set by OpenBinary.
+	rawMu sync.Mutex
+	raw   *os.File
+
+	dyn         lazy[map[elf.DynTag][]uint64]
+	symbols     lazy[[]elf.Symbol]
+	dynSymbols  lazy[[]elf.Symbol]
+	imported    lazy[[]elf.ImportedSymbol]
+	dynFuncs    lazy[[]elf.Symbol]
+	notes       lazy[[]Note]
+	dwarf       lazy[*dwarf.Data]
+	sectionMu   sync.Mutex
+	sectionData map[string][]byte
+}
+
+// lazy is a value computed at most once.
+type lazy[T any] struct {
+	once sync.Once
+	val  T
+	err  error
+}
+
+func (l *lazy[T]) get(fn func() (T, error)) (T, error) {
+	l.once.Do(func() { l.val, l.err = fn() })
+	return l.val, l.err
+}
+
+// OpenBinary opens and parses the ELF file at path. Callers must Close the
+// returned Binary when finished.
+func OpenBinary(path string) (*Binary, error) {
+	if path == "" {
+		return nil, fmt.Errorf("filename cannot be empty")
+	}
+
+	// Clean the file path to prevent directory traversal
+	cleanPath := filepath.Clean(path)
+	if _, err := os.Stat(cleanPath); err != nil {
+		return nil, fmt.Errorf("cannot access file: %w", err)
+	}
+
+	f, err := os.Open(cleanPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	file, err := elf.NewFile(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("invalid ELF file: %w", err)
+	}
+
+	return &Binary{Path: path, File: file, raw: f}, nil
+}
+
+// Close releases File and the underlying file.
+func (b *Binary) Close() error {
+	var err error
+	if b.File != nil {
+		err = b.File.Close()
+	}
+	if cerr := b.closeRaw(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// closeRaw closes the underlying file but leaves File open.
+func (b *Binary) closeRaw() error {
+	b.rawMu.Lock()
+	defer b.rawMu.Unlock()
+	if b.raw == nil {
+		return nil
+	}
+	err := b.raw.Close()
+	b.raw = nil
+	return err
+}
+
+// rawFile returns the underlying file, opening Path if the Binary was not
+// created by OpenBinary.
+func (b *Binary) rawFile() (*os.File, error) {
+	b.rawMu.Lock()
+	defer b.rawMu.Unlock()
+	if b.raw == nil {
+		f, err := os.Open(filepath.Clean(b.Path))
+		if err != nil {
+			return nil, err
+		}
+		b.raw = f
+	}
+	return b.raw, nil
+}
+
+// DynValue returns the values of the dynamic entries with the given tag. It
+// reads the .dynamic section and falls back to the PT_DYNAMIC segment, so it
+// also works on binaries whose section headers were stripped.
+func (b *Binary) DynValue(tag elf.DynTag) []uint64 {
+	entries, _ := b.dyn.get(func() (map[elf.DynTag][]uint64, error) {
+		return dynEntries(b.File, b.Path), nil
+	})
+	return entries[tag]
+}
+
+// dynEntries decodes every dynamic entry of file, preferring the .dynamic
+// section over the PT_DYNAMIC segment.
+func dynEntries(file *elf.File, name string) map[elf.DynTag][]uint64 {
+	entries := make(map[elf.DynTag][]uint64)
+	if ds := file.SectionByType(elf.SHT_DYNAMIC); ds != nil {
+		if data, err := ds.Data(); err == nil {
+			collectDynamicEntries(data, file.Class, file.ByteOrder, entries)
+		}
+	}
+	if len(entries) > 0 {
+		return entries
+	}
+	for _, prog := range file.Progs {
+		if prog.Type != elf.PT_DYNAMIC {
+			continue
+		}
+		data := make([]byte, prog.Filesz)
+		if _, err := prog.ReadAt(data, 0); err != nil {
+			output.Warnf("Error reading dynamic section for %s: %v", name, err)
+			break
+		}
+		collectDynamicEntries(data, file.Class, file.ByteOrder, entries)
+	}
+	return entries
+}
+
+// collectDynamicEntries adds the entries of a raw dynamic table to entries,
+// stopping at DT_NULL. A truncated final entry is ignored.
+func collectDynamicEntries(data []byte, class elf.Class, bo binary.ByteOrder, entries map[elf.DynTag][]uint64) {
+	if class == elf.ELFCLASS64 {
+		for i := 0; i+16 <= len(data); i += 16 {
+			tag := elf.DynTag(bo.Uint64(data[i : i+8]))
+			if tag == elf.DT_NULL {
+				return
+			}
+			entries[tag] = append(entries[tag], bo.Uint64(data[i+8:i+16]))
+		}
+		return
+	}
+	for i := 0; i+8 <= len(data); i += 8 {
+		tag := elf.DynTag(bo.Uint32(data[i : i+4]))
+		if tag == elf.DT_NULL {
+			return
+		}
+		entries[tag] = append(entries[tag], uint64(bo.Uint32(data[i+4:i+8])))
+	}
+}
+
+// Symbols returns the entries of .symtab.
+func (b *Binary) Symbols() ([]elf.Symbol, error) {
+	return b.symbols.get(b.File.Symbols)
+}
+
+// DynamicSymbols returns the entries of .dynsym.
+func (b *Binary) DynamicSymbols() ([]elf.Symbol, error) {
+	return b.dynSymbols.get(b.File.DynamicSymbols)
+}
+
+// ImportedSymbols returns the symbols the binary expects other libraries to
+// provide.
+func (b *Binary) ImportedSymbols() ([]elf.ImportedSymbol, error) {
+	return b.imported.get(b.File.ImportedSymbols)
+}
+
+// DynamicFunctions returns the function symbols found through DT_SYMTAB. Unlike
+// DynamicSymbols it does not need section headers.
+func (b *Binary) DynamicFunctions() ([]elf.Symbol, error) {
+	return b.dynFuncs.get(func() ([]elf.Symbol, error) {
+		f, err := b.rawFile()
+		if err != nil {
+			return nil, err
+		}
+		fileInfo, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		return functionsFromDynamic(b.File, f, fileInfo.Size(), b.DynValue, b.Path)
+	})
+}
+
+// HasSymbolPrefix reports whether .symtab, the imported symbols or the
+// DT_SYMTAB functions contain a symbol starting with prefix.
+func (b *Binary) HasSymbolPrefix(prefix string) bool {
+	return b.HasSymbolFunc(func(name string) bool { return strings.HasPrefix(name, prefix) })
+}
+
+// HasSymbolFunc reports whether any symbol of the binary satisfies match,
+// searching the same tables as HasSymbolPrefix.
+func (b *Binary) HasSymbolFunc(match func(name string) bool) bool {
+	if symbols, err := b.Symbols(); err == nil {
+		for _, symbol := range symbols {
+			if match(symbol.Name) {
+				return true
+			}
+		}
+	}
+	if imported, err := b.ImportedSymbols(); err == nil {
+		for _, symbol := range imported {
+			if match(symbol.Name) {
+				return true
+			}
+		}
+	}
+	if functions, err := b.DynamicFunctions(); err == nil {
+		for _, symbol := range functions {
+			if match(symbol.Name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DWARF returns the debug information of the binary. It fails when the binary
+// has no DWARF sections.
+func (b *Binary) DWARF() (*dwarf.Data, error) {
+	return b.dwarf.get(b.File.DWARF)
+}
+
+// SectionData returns the contents of the named section, or nil if the
+// binary has no such section.
+func (b *Binary) SectionData(name string) ([]byte, error) {
+	b.sectionMu.Lock()
+	defer b.sectionMu.Unlock()
+	if data, ok := b.sectionData[name]; ok {
+		return data, nil
+	}
+	section := b.File.Section(name)
+	if section == nil {
+		return nil, nil
+	}
+	data, err := section.Data()
+	if err != nil {
+		return nil, err
+	}
+	if b.sectionData == nil {
+		b.sectionData = make(map[string][]byte)
+	}
+	b.sectionData[name] = data
+	return data, nil
+}
+
+// Note is a single ELF note.
+type Note struct {
+	Name string
+	Type uint32
+	Desc []byte
+}
+
+// Notes returns the notes of every SHT_NOTE section. Files without them, such
+// as those whose section headers were stripped, fall back to the PT_NOTE
+// segments the loader reads, or to PT_GNU_PROPERTY when there are none.
+func (b *Binary) Notes() []Note {
+	notes, _ := b.notes.get(func() ([]Note, error) {
+		var notes []Note
+		for _, section := range b.File.Sections {
+			if section.Type != elf.SHT_NOTE {
+				continue
+			}
+			if data, err := section.Data(); err == nil {
+				notes = append(notes, parseNotes(data, b.File.ByteOrder, section.Addralign)...)
+			}
+		}
+		if len(notes) > 0 {
+			return notes, nil
+		}
+		typ := elf.PT_NOTE
+		if !hasProg(b.File, elf.PT_NOTE) {
+			typ = elf.PT_GNU_PROPERTY
+		}
+		for _, prog := range b.File.Progs {
+			if prog.Type != typ {
+				continue
+			}
+			data := make([]byte, prog.Filesz)
+			if _, err := prog.ReadAt(data, 0); err == nil {
+				notes = append(notes, parseNotes(data, b.File.ByteOrder, prog.Align)...)
+			}
+		}
+		return notes, nil
+	})
+	return notes
+}
+
+// parseNotes splits a note section or segment into its notes. Name and
+// descriptor are padded to align, which is 8 for notes such as
+// NT_GNU_PROPERTY_TYPE_0 on ELFCLASS64 and 4 otherwise. Truncated notes end
+// the scan.
+func parseNotes(data []byte, bo binary.ByteOrder, align uint64) []Note {
+	if align != 8 {
+		align = 4
+	}
+	pad := func(n uint64) uint64 { return (n + align - 1) &^ (align - 1) }
+
+	var notes []Note
+	for off := uint64(0); off+12 <= uint64(len(data)); {
+		namesz := uint64(bo.Uint32(data[off:]))
+		descsz := uint64(bo.Uint32(data[off+4:]))
+		typ := bo.Uint32(data[off+8:])
+		nameOff := off + 12
+		descOff := pad(nameOff + namesz)
+		if namesz > uint64(len(data)) || descsz > uint64(len(data)) || descOff+descsz > uint64(len(data)) {
+			break
+		}
+		// Only the terminator is dropped: annobin notes carry binary
+		// values, including NULs, at the end of the name.
+		name := strings.TrimSuffix(string(data[nameOff:nameOff+namesz]), "\x00")
+		notes = append(notes, Note{
+			Name: name,
+			Type: typ,
+			Desc: data[descOff : descOff+descsz],
+		})
+		off = descOff + pad(descsz)
+	}
+	return notes
+}
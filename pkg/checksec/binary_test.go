@@ -0,0 +1,129 @@
+package checksec
+
+import (
+	"debug/elf"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// buildNote encodes a single ELF note padded to align.
+func buildNote(bo binary.ByteOrder, name string, typ uint32, desc []byte, align int) []byte {
+	pad := func(b []byte) []byte {
+		for len(b)%align != 0 {
+			b = append(b, 0)
+		}
+		return b
+	}
+	hdr := make([]byte, 12)
+	bo.PutUint32(hdr[0:], uint32(len(name)+1))
+	bo.PutUint32(hdr[4:], uint32(len(desc)))
+	bo.PutUint32(hdr[8:], typ)
+	note := pad(append(hdr, append([]byte(name), 0)...))
+	return pad(append(note, desc...))
+}
+
+func TestParseNotes(t *testing.T) {
+	bo := binary.LittleEndian
+	for _, align := range []int{4, 8} {
+		prop := buildPropertyNote(bo, GnuPropertyX86Feature1Flag, GnuPropertyX86FeatureIBT)
+		data := append(buildNote(bo, "GNU", ntGNUPropertyType0, prop, align),
+			buildNote(bo, "GNU", 3, []byte{1, 2, 3, 4, 5}, align)...)
+
+		notes := parseNotes(data, bo, uint64(align))
+		if len(notes) != 2 {
+			t.Fatalf("align %d: got %d notes, want 2", align, len(notes))
+		}
+		if notes[0].Name != "GNU" || notes[0].Type != ntGNUPropertyType0 || string(notes[0].Desc) != string(prop) {
+			t.Errorf("align %d: first note = %+v", align, notes[0])
+		}
+		if notes[1].Type != 3 || len(notes[1].Desc) != 5 {
+			t.Errorf("align %d: second note = %+v", align, notes[1])
+		}
+	}
+}
+
+func TestParseNotes_Truncated(t *testing.T) {
+	bo := binary.LittleEndian
+	data := buildNote(bo, "GNU", 1, make([]byte, 16), 4)
+	if notes := parseNotes(data[:len(data)-4], bo, 4); len(notes) != 0 {
+		t.Errorf("truncated note parsed as %+v", notes)
+	}
+}
+
+func TestCollectDynamicEntries_StopsAtNull(t *testing.T) {
+	bo := binary.LittleEndian
+	data := make([]byte, 4*16)
+	bo.PutUint64(data[0:], uint64(elf.DT_NEEDED))
+	bo.PutUint64(data[8:], 1)
+	bo.PutUint64(data[16:], uint64(elf.DT_NEEDED))
+	bo.PutUint64(data[24:], 2)
+	// data[32:48] is DT_NULL; the entry after it must be ignored.
+	bo.PutUint64(data[48:], uint64(elf.DT_FLAGS))
+	bo.PutUint64(data[56:], 8)
+
+	entries := make(map[elf.DynTag][]uint64)
+	collectDynamicEntries(data, elf.ELFCLASS64, bo, entries)
+	if got := entries[elf.DT_NEEDED]; len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Errorf("DT_NEEDED = %v, want [1 2]", got)
+	}
+	if got := entries[elf.DT_FLAGS]; got != nil {
+		t.Errorf("DT_FLAGS after DT_NULL = %v, want none", got)
+	}
+}
+
+func TestOpenBinary_Errors(t *testing.T) {
+	if _, err := OpenBinary(""); err == nil || !contains(err.Error(), "filename cannot be empty") {
+		t.Errorf("empty path error = %v", err)
+	}
+	if _, err := OpenBinary(filepath.Join(t.TempDir(), "missing")); err == nil || !contains(err.Error(), "cannot access file") {
+		t.Errorf("missing file error = %v", err)
+	}
+	bad := filepath.Join(t.TempDir(), "not-elf")
+	if err := os.WriteFile(bad, []byte("not an ELF"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := OpenBinary(bad); err == nil || !contains(err.Error(), "invalid ELF file") {
+		t.Errorf("non-ELF error = %v", err)
+	}
+}
+
+func TestBinary_CachesParsedData(t *testing.T) {
+	b, err := OpenBinary(requireFixture(t, "none"))
+	if err != nil {
+		t.Fatalf("OpenBinary: %v", err)
+	}
+	defer b.Close()
+
+	first, err := b.Symbols()
+	if err != nil || len(first) == 0 {
+		t.Fatalf("Symbols() = %d symbols, %v", len(first), err)
+	}
+	second, _ := b.Symbols()
+	if &first[0] != &second[0] {
+		t.Error("Symbols() decoded the table twice")
+	}
+
+	want, _ := b.File.DynValue(elf.DT_STRTAB)
+	if got := b.DynValue(elf.DT_STRTAB); len(got) != 1 || got[0] != want[0] {
+		t.Errorf("DynValue(DT_STRTAB) = %v, want %v", got, want)
+	}
+	if !b.HasSymbolPrefix("main") {
+		t.Error("HasSymbolPrefix(main) = false")
+	}
+}
+
+func TestBinary_OpensPathOnDemand(t *testing.T) {
+	// A Binary built around an already parsed file reads the raw file from
+	// Path only when a check needs it.
+	path := requireFixture(t, "all")
+	file := loadFixture(t, "all")
+	b := &Binary{Path: path, File: file}
+	defer b.closeRaw()
+
+	funcs, err := b.DynamicFunctions()
+	if err != nil || len(funcs) == 0 {
+		t.Fatalf("DynamicFunctions() = %d, %v", len(funcs), err)
+	}
+}
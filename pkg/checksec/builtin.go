@@ -0,0 +1,168 @@
+package checksec
+
+import (
+	"debug/elf"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// IDs of the built-in checks.
+const (
+	CheckRelro     = "relro"
+	CheckCanary    = "canary"
+	CheckCfi       = "cfi"
+	CheckMTE       = "mte"
+	CheckNX        = "nx"
+	CheckPIE       = "pie"
+	CheckRPath     = "rpath"
+	CheckRunPath   = "runpath"
+	CheckSymbols   = "symbols"
+	CheckSafeStack = "safestack"
+	CheckFortify   = "fortify"
+	CheckAnnobin   = "annobin"
+	CheckCompile   = "compile_flags"
+	CheckWX        = "wx"
+	CheckTextRel   = "textrel"
+	CheckDangerous = "dangerous"
+)
+
+// builtinCheck adapts one of this package's check functions to Check.
+type builtinCheck struct {
+	id      string
+	header  string
+	columns []Column
+	run     func(b *Binary) (*Result, error)
+	details func(r Result) []string
+}
+
+func (c builtinCheck) ID() string { return c.id }
+
+func (c builtinCheck) Header() string { return c.header }
+
+// Applies is true for every binary; the built-in checks report N/A themselves
+// where a binary lacks the structures they inspect.
+func (c builtinCheck) Applies(elf.Type, elf.Machine) bool { return true }
+
+func (c builtinCheck) Run(b *Binary) Result {
+	if b.File == nil {
+		return Result{Status: StatusError, Output: "Error checking " + c.header}
+	}
+	res, err := c.run(b)
+	if err != nil {
+		return Result{Status: StatusError, Output: "Error checking " + c.header}
+	}
+	return *res
+}
+
+func (c builtinCheck) Columns() []Column {
+	if c.columns != nil {
+		return c.columns
+	}
+	return []Column{{Key: c.id, Header: c.header}}
+}
+
+func (c builtinCheck) Details(r Result) []string {
+	if c.details == nil {
+		return nil
+	}
+	return c.details(r)
+}
+
+// wrap adapts a check that cannot fail to builtinCheck.run.
+func wrap(check func(b *Binary) *Result) func(b *Binary) (*Result, error) {
+	return func(b *Binary) (*Result, error) {
+		return check(b), nil
+	}
+}
+
+// listData adapts a list accessor to Column.Data, so that machine readable
+// formats carry an array, empty rather than null when there are no items.
+func listData[T any](list func(Result) []T) func(Result) any {
+	return func(r Result) any {
+		if items := list(r); items != nil {
+			return items
+		}
+		return []T{}
+	}
+}
+
+func init() {
+	builtins := []builtinCheck{
+		{id: CheckRelro, header: "RELRO", run: wrap(relroCheck)},
+		{id: CheckCanary, header: "Stack Canary", run: wrap(canaryCheck), columns: []Column{
+			{Key: "canary", Header: "Stack Canary"},
+			{Key: "canary_mechanism", Header: "Canary Mechanism", NoTable: true, Value: func(r Result) string {
+				return string(CanaryMechanismOf(r))
+			}},
+		}},
+		{id: CheckCfi, header: "CFI", run: wrap(cfiCheck)},
+		{id: CheckMTE, header: "MTE", run: wrap(mteCheck)},
+		{id: CheckNX, header: "NX", run: wrap(nxCheck)},
+		{id: CheckPIE, header: "PIE", run: wrap(pieCheck)},
+		{id: CheckWX, header: "W^X", run: wrap(wxCheck), columns: []Column{
+			{Key: "wx", Header: "W^X"},
+			{Key: "wx_regions", Header: "WX Regions", NoTable: true, Value: func(r Result) string {
+				return WXRegionsOf(r).String()
+			}, Data: listData(func(r Result) []WXRegion {
+				regions := WXRegionsOf(r)
+				return append(regions.Segments, regions.Sections...)
+			})},
+		}},
+		{id: CheckTextRel, header: "TEXTREL", run: wrap(textrelCheck), details: func(r Result) []string {
+			var lines []string
+			for _, reloc := range TextRelocationsOf(r).Relocations {
+				lines = append(lines, reloc.String())
+			}
+			return lines
+		}},
+		{id: CheckRPath, header: "RPATH", run: wrap(rpathCheck), details: searchPathDetails, columns: []Column{
+			{Key: "rpath", Header: "RPATH"},
+			{Key: "rpath_paths", Header: "RPATH Entries", NoTable: true, Value: searchPathText, Spans: searchPathSpans, Data: listData(SearchPathOf)},
+		}},
+		{id: CheckRunPath, header: "RUNPATH", run: wrap(runpathCheck), details: searchPathDetails, columns: []Column{
+			{Key: "runpath", Header: "RUNPATH"},
+			{Key: "runpath_paths", Header: "RUNPATH Entries", NoTable: true, Value: searchPathText, Spans: searchPathSpans, Data: listData(SearchPathOf)},
+		}},
+		{id: CheckSymbols, header: "Symbols", run: wrap(symbolsCheck)},
+		{id: CheckSafeStack, header: "SafeStack", run: wrap(safeStackCheck)},
+		{id: CheckFortify, header: "FORTIFY", run: fortifyCheck, columns: []Column{
+			{Key: "fortify_source", Header: "FORTIFY"},
+			{Key: "fortified", Header: "Fortified", Value: func(r Result) string {
+				return strconv.Itoa(FortifyDetailsOf(r).Fortified)
+			}},
+			{Key: "fortifyable", Header: "Fortifiable", Value: func(r Result) string {
+				return strconv.Itoa(FortifyDetailsOf(r).Fortifiable)
+			}},
+		}},
+		{id: CheckDangerous, header: "Dangerous", run: wrap(dangerousCheck), columns: []Column{
+			{Key: "dangerous", Header: "Dangerous"},
+			{Key: "dangerous_functions", Header: "Dangerous Functions", NoTable: true, Value: func(r Result) string {
+				return strings.Join(DangerousFunctionsOf(r), ",")
+			}, Data: listData(DangerousFunctionsOf)},
+		}},
+		{id: CheckAnnobin, header: "Annobin", run: wrap(annobinCheck), columns: []Column{
+			{Key: "annobin", Header: "Annobin"},
+			{Key: "annobin_flags", Header: "Compiled Options", NoTable: true, Value: func(r Result) string {
+				return AnnobinFlagsOf(r).String()
+			}},
+			{Key: "annobin_mismatch", Header: "Annobin Mismatch", NoTable: true, Value: func(r Result) string {
+				return strings.Join(AnnobinFlagsOf(r).Mismatches, "; ")
+			}, Data: listData(func(r Result) []string {
+				return AnnobinFlagsOf(r).Mismatches
+			})},
+		}},
+		{id: CheckCompile, header: "Compile Flags", run: wrap(compileFlagsCheck), columns: []Column{
+			{Key: "compile_flags", Header: "Compile Flags"},
+			{Key: "unhardened_units", Header: "Unhardened Units", NoTable: true, Value: func(r Result) string {
+				return CompileFlagsOf(r).String()
+			}, Data: listData(func(r Result) []CompileUnit {
+				flags := CompileFlagsOf(r)
+				return append(slices.Clip(flags.Unhardened), flags.Unrecorded...)
+			})},
+		}},
+	}
+	for _, c := range builtins {
+		Register(c)
+	}
+}
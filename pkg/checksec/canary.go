@@ -1,95 +1,174 @@
 package checksec
 
 import (
-	"bytes"
-	"context"
 	"debug/elf"
-	"fmt"
-	"os"
-	"path/filepath"
-	"time"
+	"encoding/binary"
+	"strings"
 )
 
-// CanaryResult struct
-type CanaryResult struct {
-	Output string
-	Color  string
-}
-
 // StackChk to check for stack_chk_fail value
 const StackChk = "__stack_chk_fail"
 
-// Canary - Check for canary bits
-func Canary(name string) (*CanaryResult, error) {
-	// Input validation
-	if name == "" {
-		return nil, fmt.Errorf("filename cannot be empty")
-	}
+// CanaryMechanism names how a binary checks its stack canary.
+type CanaryMechanism string
 
-	// Clean the file path to prevent directory traversal
-	cleanPath := filepath.Clean(name)
+const (
+	// CanaryStackChkFail is the __stack_chk_fail handler of GCC and Clang,
+	// provided by glibc, musl and bionic.
+	CanaryStackChkFail CanaryMechanism = "__stack_chk_fail"
+	// CanaryStackChkFailLocal is the hidden wrapper i386 and other PIC code
+	// call instead of __stack_chk_fail.
+	CanaryStackChkFailLocal CanaryMechanism = "__stack_chk_fail_local"
+	// CanaryStackChkGuard is the global guard value used by targets whose
+	// canary is not kept in thread-local storage.
+	CanaryStackChkGuard CanaryMechanism = "__stack_chk_guard"
+	// CanaryIntelCookie is the security cookie of the Intel C compiler.
+	CanaryIntelCookie CanaryMechanism = "__intel_security_cookie"
+	// CanaryTLS is a read of the thread-local guard found in the code of a
+	// static binary without the symbols above.
+	CanaryTLS CanaryMechanism = "TLS guard"
+)
 
-	// Check file exists and is accessible
-	if _, err := os.Stat(cleanPath); err != nil {
-		return nil, fmt.Errorf("cannot access file: %w", err)
-	}
+// canarySymbols are the symbols of each mechanism, in order of preference.
+var canarySymbols = []CanaryMechanism{CanaryStackChkFail, CanaryStackChkFailLocal, CanaryStackChkGuard, CanaryIntelCookie}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+// Canary - Check for canary bits
+func Canary(name string) (*Result, error) {
+	b, err := OpenBinary(name)
+	if err != nil {
+		return nil, err
+	}
+	defer b.Close()
+	return canaryCheck(b), nil
+}
 
-	select {
-	case <-ctx.Done():
-		return nil, fmt.Errorf("operation timed out")
+// canaryCheck looks for the canary symbols in the symbol tables of b and, for
+// static binaries without them, for reads of the thread-local guard. The libc
+// linked into a static binary reads the guard whenever the libc itself was
+// built with a stack protector, so such reads leave the program's own code
+// unknown.
+func canaryCheck(b *Binary) *Result {
+	switch mechanism := canaryMechanism(b); mechanism {
+	case "":
+		return &Result{Status: StatusFail, Output: "No Canary Found", Value: mechanism}
+	case CanaryTLS:
+		return &Result{Status: StatusUnknown, Output: "Canary Unknown (TLS guard)", Value: mechanism}
 	default:
+		return &Result{Status: StatusPass, Output: "Canary Found", Value: mechanism}
 	}
+}
 
-	f, err := os.Open(cleanPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %w", err)
+// canaryMechanism returns the canary mechanism of b, or "" if none is found.
+func canaryMechanism(b *Binary) CanaryMechanism {
+	for _, mechanism := range canarySymbols {
+		if b.HasSymbolFunc(func(name string) bool {
+			name, _, _ = strings.Cut(name, "@")
+			return name == string(mechanism)
+		}) {
+			return mechanism
+		}
 	}
-	defer f.Close()
+	if isStatic(b) && readsTLSGuard(b.File) {
+		return CanaryTLS
+	}
+	return ""
+}
 
-	file, err := elf.NewFile(f)
-	if err != nil {
-		return nil, fmt.Errorf("invalid ELF file: %w", err)
+// CanaryMechanismOf returns the mechanism carried by a canary result.
+func CanaryMechanismOf(r Result) CanaryMechanism {
+	mechanism, _ := r.Value.(CanaryMechanism)
+	return mechanism
+}
+
+// readsTLSGuard reports whether the executable PT_LOAD segments of file load
+// or compare the stack guard at its fixed thread-local offset: %fs:0x28 on
+// x86-64 (glibc and musl), %gs:0x14 on i386 and TPIDR_EL0+0x28 on AArch64
+// (bionic).
+func readsTLSGuard(file *elf.File) bool {
+	var match func([]byte) bool
+	switch file.Machine {
+	case elf.EM_X86_64:
+		match = readsX86_64Guard
+	case elf.EM_386:
+		match = reads386Guard
+	case elf.EM_AARCH64:
+		match = func(code []byte) bool { return readsAArch64Guard(code, file.ByteOrder) }
+	default:
+		return false
+	}
+	for _, prog := range file.Progs {
+		if prog.Type != elf.PT_LOAD || prog.Flags&elf.PF_X == 0 {
+			continue
+		}
+		code := make([]byte, prog.Filesz)
+		if _, err := prog.ReadAt(code, 0); err != nil {
+			continue
+		}
+		if match(code) {
+			return true
+		}
 	}
+	return false
+}
 
-	res := &CanaryResult{}
+// guardOps are the opcodes that read the guard: mov, sub, xor and cmp with a
+// register destination.
+var guardOps = map[byte]bool{0x8b: true, 0x2b: true, 0x33: true, 0x3b: true}
 
-	// Check symbols with proper error handling
-	if symbols, err := file.Symbols(); err == nil {
-		for _, symbol := range symbols {
-			if bytes.HasPrefix([]byte(symbol.Name), []byte(StackChk)) {
-				res.Output = "Canary Found"
-				res.Color = "green"
-				return res, nil
-			}
+// readsX86_64Guard matches "op %fs:0x28,%reg", encoded as the fs prefix, a
+// REX.W prefix, the opcode, a ModRM byte selecting a SIB byte without base or
+// index and the 32-bit displacement.
+func readsX86_64Guard(code []byte) bool {
+	for i := 0; i+9 <= len(code); i++ {
+		if code[i] == 0x64 && code[i+1]&0xfb == 0x48 && guardOps[code[i+2]] && code[i+3]&0xc7 == 0x04 &&
+			code[i+4] == 0x25 && code[i+5] == 0x28 && code[i+6] == 0 && code[i+7] == 0 && code[i+8] == 0 {
+			return true
 		}
 	}
+	return false
+}
 
-	// Check imported symbols
-	if importedSymbols, err := file.ImportedSymbols(); err == nil {
-		for _, imp := range importedSymbols {
-			if bytes.HasPrefix([]byte(imp.Name), []byte(StackChk)) {
-				res.Output = "Canary Found"
-				res.Color = "green"
-				return res, nil
-			}
+// reads386Guard matches "mov %gs:0x14,%eax" and "op %gs:0x14,%reg", encoded
+// as the gs prefix, the opcode, a ModRM byte selecting a 32-bit displacement
+// and the displacement.
+func reads386Guard(code []byte) bool {
+	disp := func(i int) bool {
+		return i+4 <= len(code) && code[i] == 0x14 && code[i+1] == 0 && code[i+2] == 0 && code[i+3] == 0
+	}
+	for i := 0; i+6 <= len(code); i++ {
+		if code[i] != 0x65 {
+			continue
+		}
+		if code[i+1] == 0xa1 && disp(i+2) {
+			return true
+		}
+		if guardOps[code[i+1]] && code[i+2]&0xc7 == 0x05 && disp(i+3) {
+			return true
 		}
 	}
+	return false
+}
 
-	// Check dynamic functions
-	if dynamicFunctions, err := FunctionsFromSymbolTable(f); err == nil {
-		for _, symbol := range dynamicFunctions {
-			if bytes.HasPrefix([]byte(symbol.Name), []byte(StackChk)) {
-				res.Output = "Canary Found"
-				res.Color = "green"
-				return res, nil
+// readsAArch64Guard matches "mrs xN, tpidr_el0" followed within a few
+// instructions by "ldr xM, [xN, #0x28]".
+func readsAArch64Guard(code []byte, bo binary.ByteOrder) bool {
+	const (
+		mrsTPIDR = 0xd53bd040 // mrs x0, tpidr_el0
+		ldr0x28  = 0xf9401400 // ldr x0, [x0, #0x28]
+		window   = 4
+	)
+	for i := 0; i+4 <= len(code); i += 4 {
+		insn := bo.Uint32(code[i:])
+		if insn&^0x1f != mrsTPIDR {
+			continue
+		}
+		reg := insn & 0x1f
+		for j := i + 4; j < i+4+window*4 && j+4 <= len(code); j += 4 {
+			next := bo.Uint32(code[j:])
+			if next&0xfffffc00 == ldr0x28 && (next>>5)&0x1f == reg {
+				return true
 			}
 		}
 	}
-
-	res.Output = "No Canary Found"
-	res.Color = "red"
-	return res, nil
+	return false
 }
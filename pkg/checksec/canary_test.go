@@ -1,7 +1,10 @@
 package checksec
 
 import (
+	"debug/elf"
+	"encoding/binary"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
 )
@@ -100,6 +103,100 @@ func TestCanary_ValidELFWithCanary(t *testing.T) {
 	t.Skip("Requires test ELF binaries - skipping for now")
 }
 
+func TestCanaryMechanism_Fixtures(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    CanaryMechanism
+	}{
+		{"all", CanaryStackChkFail},
+		{"dso.so", CanaryStackChkFail},
+		{"none", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			b, err := OpenBinary(requireFixture(t, tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer b.Close()
+			if got := CanaryMechanismOf(*canaryCheck(b)); got != tt.want {
+				t.Errorf("mechanism = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadsTLSGuard(t *testing.T) {
+	le := func(words ...uint32) []byte {
+		var code []byte
+		for _, w := range words {
+			code = binary.LittleEndian.AppendUint32(code, w)
+		}
+		return code
+	}
+	tests := []struct {
+		name  string
+		match func([]byte) bool
+		code  []byte
+		want  bool
+	}{
+		{"x86-64 mov %fs:0x28,%rax", readsX86_64Guard, []byte{0x90, 0x64, 0x48, 0x8b, 0x04, 0x25, 0x28, 0, 0, 0}, true},
+		{"x86-64 sub %fs:0x28,%rdx", readsX86_64Guard, []byte{0x64, 0x48, 0x2b, 0x14, 0x25, 0x28, 0, 0, 0}, true},
+		{"x86-64 mov %fs:0x28,%r8", readsX86_64Guard, []byte{0x64, 0x4c, 0x8b, 0x04, 0x25, 0x28, 0, 0, 0}, true},
+		{"x86-64 other offset", readsX86_64Guard, []byte{0x64, 0x48, 0x8b, 0x04, 0x25, 0x30, 0, 0, 0}, false},
+		{"x86-64 store", readsX86_64Guard, []byte{0x64, 0x48, 0x89, 0x04, 0x25, 0x28, 0, 0, 0}, false},
+		{"i386 mov %gs:0x14,%eax", reads386Guard, []byte{0x65, 0xa1, 0x14, 0, 0, 0}, true},
+		{"i386 xor %gs:0x14,%edx", reads386Guard, []byte{0x65, 0x33, 0x15, 0x14, 0, 0, 0}, true},
+		{"i386 truncated", reads386Guard, []byte{0x65, 0xa1, 0x14, 0}, false},
+		{"aarch64 mrs and ldr", func(c []byte) bool { return readsAArch64Guard(c, binary.LittleEndian) }, le(0xd53bd048, 0xd503201f, 0xf9401509), true},
+		{"aarch64 other register", func(c []byte) bool { return readsAArch64Guard(c, binary.LittleEndian) }, le(0xd53bd048, 0xf9401529), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.match(tt.code); got != tt.want {
+				t.Errorf("match(% x) = %v, want %v", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanary_StrippedStatic(t *testing.T) {
+	// The libc reads the guard in both builds, so neither tells whether
+	// main has a canary.
+	for _, protector := range []string{"-fstack-protector-strong", "-fno-stack-protector"} {
+		t.Run(protector, func(t *testing.T) {
+			tempDir := t.TempDir()
+			src := filepath.Join(tempDir, "main.c")
+			bin := filepath.Join(tempDir, "app")
+
+			code := "#include <string.h>\nint main(int c, char **v) { char b[64]; strcpy(b, v[0]); return b[c]; }\n"
+			if err := os.WriteFile(src, []byte(code), 0o644); err != nil {
+				t.Fatalf("write source: %v", err)
+			}
+			cmd := exec.Command("gcc", "-static", "-s", protector, "-o", bin, src)
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Skipf("cannot build static test ELF: %v (%s)", err, out)
+			}
+
+			b, err := OpenBinary(bin)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer b.Close()
+			if b.File.Machine != elf.EM_X86_64 && b.File.Machine != elf.EM_386 && b.File.Machine != elf.EM_AARCH64 {
+				t.Skipf("no TLS guard pattern for %v", b.File.Machine)
+			}
+			if !readsTLSGuard(b.File) {
+				t.Skip("the static libc was built without a stack protector")
+			}
+			res := canaryCheck(b)
+			if res.Status != StatusUnknown || CanaryMechanismOf(*res) != CanaryTLS {
+				t.Errorf("canaryCheck = %s %q, want unknown with %q", res.Status, CanaryMechanismOf(*res), CanaryTLS)
+			}
+		})
+	}
+}
+
 func TestCanary_PathTraversal(t *testing.T) {
 	// Test various path traversal attempts
 	maliciousPaths := []string{
@@ -1,18 +1,20 @@
 package checksec
 
 import (
-	"context"
 	"debug/elf"
 	"encoding/binary"
-	"fmt"
-	"os"
-	"path/filepath"
-	"time"
 )
 
-type CfiResult struct {
-	Output string
-	Color  string
+// CfiFeatures is the machine readable value of the Cfi check.
+type CfiFeatures struct {
+	SHSTK bool `json:"shstk"`
+	IBT   bool `json:"ibt"`
+	PAC   bool `json:"pac"`
+	BTI   bool `json:"bti"`
+	// GCS is the AArch64 Guarded Control Stack.
+	GCS bool `json:"gcs"`
+	// Clang is the Clang CFI mode: "multi", "single" or "none".
+	Clang string `json:"clang"`
 }
 
 type x86CET struct {
@@ -23,6 +25,7 @@ type x86CET struct {
 type armPACBTI struct {
 	pac bool
 	bti bool
+	gcs bool
 }
 
 const GnuPropertyArmFeature1Flag uint32 = 0xc0000000
@@ -36,76 +39,71 @@ const (
 const (
 	GnuPropertyArmFeatureBTI uint32 = 1 << iota
 	GnuPropertyArmFeaturePAC
+	GnuPropertyArmFeatureGCS
 )
 
-// Cfi - Check for Control Flow Integrity features
-func Cfi(name string) (*CfiResult, error) {
-	// Input validation
-	if name == "" {
-		return nil, fmt.Errorf("filename cannot be empty")
-	}
-
-	// Clean the file path to prevent directory traversal
-	cleanPath := filepath.Clean(name)
-
-	// Check file exists and is accessible
-	if _, err := os.Stat(cleanPath); err != nil {
-		return nil, fmt.Errorf("cannot access file: %w", err)
-	}
-
-	// Open with timeout context to prevent hanging operations
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+// ntGNUPropertyType0 is the type of the GNU program property note.
+const ntGNUPropertyType0 = 5
 
-	// Use context for file operations
-	select {
-	case <-ctx.Done():
-		return nil, fmt.Errorf("operation timed out")
-	default:
-	}
-
-	f, err := os.Open(cleanPath)
+// Cfi - Check for Control Flow Integrity features
+func Cfi(name string) (*Result, error) {
+	b, err := OpenBinary(name)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %w", err)
+		return nil, err
 	}
-	defer f.Close()
+	defer b.Close()
+	return cfiCheck(b), nil
+}
 
-	file, err := elf.NewFile(f)
-	if err != nil {
-		return nil, fmt.Errorf("invalid ELF file: %w", err)
+// gnuProperties returns the concatenated property arrays of the binary's
+// NT_GNU_PROPERTY_TYPE_0 notes, and whether any such note exists.
+func gnuProperties(b *Binary) ([]byte, bool) {
+	var data []byte
+	found := false
+	for _, note := range b.Notes() {
+		if note.Name == "GNU" && note.Type == ntGNUPropertyType0 {
+			data = append(data, note.Desc...)
+			found = true
+		}
 	}
+	return data, found
+}
 
-	res := &CfiResult{}
+// cfiCheck reports the hardware and Clang CFI features of b.
+func cfiCheck(b *Binary) *Result {
+	file := b.File
+	res := &Result{}
+	features := CfiFeatures{}
 	var hwOutput string
-	var hwColor string
-	notes := file.Section(".note.gnu.property")
-	if notes == nil {
+	var hwStatus Status
+	propertyData, ok := gnuProperties(b)
+	if !ok {
 		resUnknown(res)
-		return res, nil
+		return res
 	}
 
-	propertyData, err := notes.Data()
-	if err != nil {
-		resUnknown(res)
-		return res, nil
-	}
-
-	// Property data layout of the relevant sections in ELFCLASS64
+	// Property data layout of the relevant sections in ELFCLASS64; in
+	// ELFCLASS32 the pad is omitted, as properties are 4-byte aligned.
 	// |0                  |1
 	// |0|1|2|3|4|5|6|7|8|9|0|1|2|3|4|5|
 	// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
 	// | type  |datasz | btmsk |  pad  |
 	// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
-	if file.Class == elf.ELFCLASS64 && file.Machine == elf.EM_X86_64 {
-		// x86-64, check for Shadow Stack and IBT
+	if file.Machine == elf.EM_X86_64 || file.Machine == elf.EM_386 {
+		// x86-64, x32 and i386, check for Shadow Stack and IBT
 		// https://docs.kernel.org/next/x86/shstk.html
 		// https://www.intel.com/content/www/us/en/developer/articles/technical/technical-look-control-flow-enforcement-technology.html
-		hwOutput, hwColor = cetOutputString(parseX86CETFromNotes(propertyData, file.ByteOrder))
+		cet := parseX86CETFromNotes(propertyData, file.ByteOrder, file.Class)
+		features.SHSTK, features.IBT = cet.shstk, cet.ibt
+		hwOutput, hwStatus = cetOutputString(cet)
 	} else if file.Class == elf.ELFCLASS64 && file.Machine == elf.EM_AARCH64 {
-		// AARCH64, check for PAC and BTI
+		// AARCH64, check for PAC, BTI and GCS
 		// https://docs.kernel.org/arch/arm64/pointer-authentication.html
+		// https://docs.kernel.org/arch/arm64/gcs.html
 		// https://community.arm.com/arm-community-blogs/b/architectures-and-processors-blog/posts/armv8-1-m-pointer-authentication-and-branch-target-identification-extension
-		hwOutput, hwColor = armOutputString(parseArmPACBTIFromNotes(propertyData, file.ByteOrder))
+		arm := parseArmPACBTIFromNotes(propertyData, file.ByteOrder, file.Class)
+		features.PAC, features.BTI, features.GCS = arm.pac, arm.bti, arm.gcs
+		hwOutput, hwStatus = armOutputString(arm)
 	} else {
 		// Leave hwOutput empty; fallback to Unknown unless Clang CFI is detected
 	}
@@ -113,35 +111,31 @@ func Cfi(name string) (*CfiResult, error) {
 	// Detect Clang CFI presence and classify Single-Module vs Multi-Module
 	clangMode := "none"
 	// Errors from reading symbols are treated as absence of Clang CFI
-	var allSyms []elf.Symbol
-	if syms, err := file.Symbols(); err == nil {
-		allSyms = syms
-	}
-	var dynSyms []elf.Symbol
-	if dsyms, err := file.DynamicSymbols(); err == nil {
-		dynSyms = dsyms
-	}
+	allSyms, _ := b.Symbols()
+	dynSyms, _ := b.DynamicSymbols()
 	clangMode = classifyClangCFIMode(allSyms, dynSyms)
+	features.Clang = clangMode
+	res.Value = features
 
 	// Build output string
 	if hwOutput == "" {
 		// No known HW CFI parsed
 		if clangMode == "none" {
 			resUnknown(res)
-			return res, nil
+			return res
 		}
 		// Only Clang CFI detected
-		res.Color = "green"
+		res.Status = StatusPass
 		if clangMode == "multi" {
 			res.Output = "Clang CFI: Multi-Module"
 		} else {
 			res.Output = "Clang CFI: Single-Module"
 		}
-		return res, nil
+		return res
 	}
 
 	// Combine HW and Clang CFI info
-	res.Color = hwColor
+	res.Status = hwStatus
 	if clangMode == "none" {
 		res.Output = hwOutput
 	} else if clangMode == "multi" {
@@ -150,13 +144,14 @@ func Cfi(name string) (*CfiResult, error) {
 		res.Output = hwOutput + " | Clang CFI: Single-Module"
 	}
 
-	return res, nil
+	return res
 }
 
-// parseX86CETFromNotes walks a .note.gnu.property payload and returns the x86
-// CET (SHSTK/IBT) features advertised. It is bounds-safe on truncated or
-// malformed input: out-of-range reads stop the scan rather than panicking.
-func parseX86CETFromNotes(data []byte, bo binary.ByteOrder) x86CET {
+// parseX86CETFromNotes walks a .note.gnu.property payload of an ELF file of
+// the given class and returns the x86 CET (SHSTK/IBT) features advertised. It
+// is bounds-safe on truncated or malformed input: out-of-range reads stop the
+// scan rather than panicking.
+func parseX86CETFromNotes(data []byte, bo binary.ByteOrder, class elf.Class) x86CET {
 	var parsed x86CET
 	i := 0
 	for i+8 <= len(data) {
@@ -164,10 +159,10 @@ func parseX86CETFromNotes(data []byte, bo binary.ByteOrder) x86CET {
 		datasz := bo.Uint32(data[i+4 : i+8])
 		i += 8
 
-		// The payload is datasz bytes, padded to 8-byte (ELFCLASS64) alignment.
+		// The payload is datasz bytes, padded to the alignment of the class.
 		// Advance by the full padded length so non-feature properties (datasz != 4)
 		// don't desync the scan and hide a following feature property.
-		payloadLen := align8(datasz)
+		payloadLen := alignProperty(datasz, class)
 		if i+int(datasz) > len(data) {
 			break
 		}
@@ -179,15 +174,20 @@ func parseX86CETFromNotes(data []byte, bo binary.ByteOrder) x86CET {
 	return parsed
 }
 
-// align8 rounds n up to the next multiple of 8 (the GNU property note alignment
-// for ELFCLASS64), returned as an int for use as a slice offset.
-func align8(n uint32) int {
+// alignProperty rounds n up to the GNU property alignment of class: 8 bytes
+// for ELFCLASS64 and 4 bytes for ELFCLASS32. It is returned as an int for use
+// as a slice offset.
+func alignProperty(n uint32, class elf.Class) int {
+	if class == elf.ELFCLASS32 {
+		return int((uint64(n) + 3) &^ 3)
+	}
 	return int((uint64(n) + 7) &^ 7)
 }
 
-// parseArmPACBTIFromNotes walks a .note.gnu.property payload and returns the
-// AArch64 PAC/BTI features advertised. It is bounds-safe on truncated input.
-func parseArmPACBTIFromNotes(data []byte, bo binary.ByteOrder) armPACBTI {
+// parseArmPACBTIFromNotes walks a .note.gnu.property payload of an ELF file of
+// the given class and returns the AArch64 PAC/BTI/GCS features advertised. It is
+// bounds-safe on truncated input.
+func parseArmPACBTIFromNotes(data []byte, bo binary.ByteOrder, class elf.Class) armPACBTI {
 	var parsed armPACBTI
 	i := 0
 	for i+8 <= len(data) {
@@ -197,7 +197,7 @@ func parseArmPACBTIFromNotes(data []byte, bo binary.ByteOrder) armPACBTI {
 
 		// Advance by the full padded payload so non-feature properties don't
 		// desync the scan (see parseX86CETFromNotes).
-		payloadLen := align8(datasz)
+		payloadLen := alignProperty(datasz, class)
 		if i+int(datasz) > len(data) {
 			break
 		}
@@ -209,32 +209,40 @@ func parseArmPACBTIFromNotes(data []byte, bo binary.ByteOrder) armPACBTI {
 	return parsed
 }
 
-// cetOutputString maps parsed x86 CET features to the display string and color.
-func cetOutputString(s x86CET) (output, color string) {
+// cetOutputString maps parsed x86 CET features to the display string and status.
+func cetOutputString(s x86CET) (string, Status) {
 	switch {
 	case s.shstk && s.ibt:
-		return "SHSTK & IBT", "green"
+		return "SHSTK & IBT", StatusPass
 	case s.shstk:
-		return "SHSTK & NO IBT", "yellow"
+		return "SHSTK & NO IBT", StatusPartial
 	case s.ibt:
-		return "NO SHSTK & IBT", "yellow"
+		return "NO SHSTK & IBT", StatusPartial
 	default:
-		return "NO SHSTK & NO IBT", "red"
+		return "NO SHSTK & NO IBT", StatusFail
 	}
 }
 
-// armOutputString maps parsed AArch64 PAC/BTI features to the display string and color.
-func armOutputString(s armPACBTI) (output, color string) {
+// armOutputString maps parsed AArch64 PAC/BTI features to the display string
+// and status. A Guarded Control Stack is appended as " & GCS"; as few
+// toolchains enable it yet, its absence does not lower the status.
+func armOutputString(s armPACBTI) (string, Status) {
+	var out string
+	var status Status
 	switch {
 	case s.pac && s.bti:
-		return "PAC & BTI", "green"
+		out, status = "PAC & BTI", StatusPass
 	case s.pac:
-		return "PAC & NO BTI", "yellow"
+		out, status = "PAC & NO BTI", StatusPartial
 	case s.bti:
-		return "NO PAC & BTI", "yellow"
+		out, status = "NO PAC & BTI", StatusPartial
 	default:
-		return "NO PAC & NO BTI", "red"
+		out, status = "NO PAC & NO BTI", StatusFail
+	}
+	if s.gcs {
+		out += " & GCS"
 	}
+	return out, status
 }
 
 func parseBitmaskForx86CET(bitmask uint32) x86CET {
@@ -260,6 +268,7 @@ func parseBitmaskForArmPACBTI(bitmask uint32) armPACBTI {
 	result := armPACBTI{
 		pac: false,
 		bti: false,
+		gcs: false,
 	}
 	for bitmask > 0 {
 		bit := bitmask & (-bitmask)
@@ -270,13 +279,15 @@ func parseBitmaskForArmPACBTI(bitmask uint32) armPACBTI {
 			result.pac = true
 		case GnuPropertyArmFeatureBTI:
 			result.bti = true
+		case GnuPropertyArmFeatureGCS:
+			result.gcs = true
 		}
 	}
 	return result
 }
 
-func resUnknown(emptyCfi *CfiResult) {
-	emptyCfi.Color = "yellow"
+func resUnknown(emptyCfi *Result) {
+	emptyCfi.Status = StatusUnknown
 	emptyCfi.Output = "Unknown"
 }
 
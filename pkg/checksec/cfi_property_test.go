@@ -20,7 +20,8 @@ func TestCetOutputString(t *testing.T) {
 		{x86CET{shstk: false, ibt: false}, "NO SHSTK & NO IBT", "red"},
 	}
 	for _, c := range cases {
-		gotOut, gotColor := cetOutputString(c.in)
+		gotOut, gotStatus := cetOutputString(c.in)
+		gotColor := gotStatus.Color()
 		if gotOut != c.wantOut || gotColor != c.wantColor {
 			t.Errorf("cetOutputString(%+v) = %q/%q, want %q/%q", c.in, gotOut, gotColor, c.wantOut, c.wantColor)
 		}
@@ -37,9 +38,12 @@ func TestArmOutputString(t *testing.T) {
 		{armPACBTI{pac: true, bti: false}, "PAC & NO BTI", "yellow"},
 		{armPACBTI{pac: false, bti: true}, "NO PAC & BTI", "yellow"},
 		{armPACBTI{pac: false, bti: false}, "NO PAC & NO BTI", "red"},
+		{armPACBTI{pac: true, bti: true, gcs: true}, "PAC & BTI & GCS", "green"},
+		{armPACBTI{pac: false, bti: true, gcs: true}, "NO PAC & BTI & GCS", "yellow"},
 	}
 	for _, c := range cases {
-		gotOut, gotColor := armOutputString(c.in)
+		gotOut, gotStatus := armOutputString(c.in)
+		gotColor := gotStatus.Color()
 		if gotOut != c.wantOut || gotColor != c.wantColor {
 			t.Errorf("armOutputString(%+v) = %q/%q, want %q/%q", c.in, gotOut, gotColor, c.wantOut, c.wantColor)
 		}
@@ -60,7 +64,7 @@ func TestProp_X86Bitmask_Oracle(t *testing.T) {
 	})
 }
 
-// parseBitmaskForArmPACBTI must set exactly the PAC/BTI flags corresponding to
+// parseBitmaskForArmPACBTI must set exactly the PAC/BTI/GCS flags corresponding to
 // the GNU property feature bits.
 func TestProp_ArmBitmask_Oracle(t *testing.T) {
 	rapid.Check(t, func(t *rapid.T) {
@@ -68,8 +72,9 @@ func TestProp_ArmBitmask_Oracle(t *testing.T) {
 		got := parseBitmaskForArmPACBTI(m)
 		wantBTI := m&GnuPropertyArmFeatureBTI != 0
 		wantPAC := m&GnuPropertyArmFeaturePAC != 0
-		if got.bti != wantBTI || got.pac != wantPAC {
-			t.Fatalf("mask=%#x got=%+v want bti=%v pac=%v", m, got, wantBTI, wantPAC)
+		wantGCS := m&GnuPropertyArmFeatureGCS != 0
+		if got.bti != wantBTI || got.pac != wantPAC || got.gcs != wantGCS {
+			t.Fatalf("mask=%#x got=%+v want bti=%v pac=%v gcs=%v", m, got, wantBTI, wantPAC, wantGCS)
 		}
 	})
 }
@@ -108,7 +113,7 @@ func TestX86Notes_SkipsNon4ByteProperty(t *testing.T) {
 	feat := buildPropertyNote(bo, GnuPropertyX86Feature1Flag, GnuPropertyX86FeatureIBT|GnuPropertyX86FeatureSHSTK)
 	data := append(lead, feat...)
 
-	got := parseX86CETFromNotes(data, bo)
+	got := parseX86CETFromNotes(data, bo, elf.ELFCLASS64)
 	if !got.ibt || !got.shstk {
 		t.Fatalf("feature property after an 8-byte property was missed: %+v", got)
 	}
@@ -123,12 +128,30 @@ func TestArmNotes_SkipsNon4ByteProperty(t *testing.T) {
 	feat := buildPropertyNote(bo, GnuPropertyArmFeature1Flag, GnuPropertyArmFeaturePAC|GnuPropertyArmFeatureBTI)
 	data := append(lead, feat...)
 
-	got := parseArmPACBTIFromNotes(data, bo)
+	got := parseArmPACBTIFromNotes(data, bo, elf.ELFCLASS64)
 	if !got.pac || !got.bti {
 		t.Fatalf("feature property after an 8-byte property was missed: %+v", got)
 	}
 }
 
+// ELFCLASS32 properties are 4-byte aligned: a 4-byte property followed by a
+// feature property must not be read with the 8-byte ELFCLASS64 padding.
+func TestX86Notes_ELFCLASS32Alignment(t *testing.T) {
+	bo := binary.LittleEndian
+	data := make([]byte, 24)
+	// GNU_PROPERTY_X86_ISA_1_USED, datasz=4, no padding.
+	bo.PutUint32(data[0:4], 0xc0010002)
+	bo.PutUint32(data[4:8], 4)
+	bo.PutUint32(data[8:12], 1)
+	bo.PutUint32(data[12:16], GnuPropertyX86Feature1Flag)
+	bo.PutUint32(data[16:20], 4)
+	bo.PutUint32(data[20:24], GnuPropertyX86FeatureIBT|GnuPropertyX86FeatureSHSTK)
+
+	if got := parseX86CETFromNotes(data, bo, elf.ELFCLASS32); !got.ibt || !got.shstk {
+		t.Fatalf("feature property after a 4-byte property was missed: %+v", got)
+	}
+}
+
 // A well-formed single x86 feature note must yield the same result as parsing
 // its bitmask directly.
 func TestProp_X86Notes_SingleRecordOracle(t *testing.T) {
@@ -136,7 +159,7 @@ func TestProp_X86Notes_SingleRecordOracle(t *testing.T) {
 		rapid.Check(t, func(t *rapid.T) {
 			mask := rapid.Uint32().Draw(t, "mask")
 			note := buildPropertyNote(bo, GnuPropertyX86Feature1Flag, mask)
-			if got, want := parseX86CETFromNotes(note, bo), parseBitmaskForx86CET(mask); got != want {
+			if got, want := parseX86CETFromNotes(note, bo, elf.ELFCLASS64), parseBitmaskForx86CET(mask); got != want {
 				t.Fatalf("mask=%#x got=%+v want=%+v", mask, got, want)
 			}
 		})
@@ -150,7 +173,7 @@ func TestProp_ArmNotes_SingleRecordOracle(t *testing.T) {
 		rapid.Check(t, func(t *rapid.T) {
 			mask := rapid.Uint32().Draw(t, "mask")
 			note := buildPropertyNote(bo, GnuPropertyArmFeature1Flag, mask)
-			if got, want := parseArmPACBTIFromNotes(note, bo), parseBitmaskForArmPACBTI(mask); got != want {
+			if got, want := parseArmPACBTIFromNotes(note, bo, elf.ELFCLASS64), parseBitmaskForArmPACBTI(mask); got != want {
 				t.Fatalf("mask=%#x got=%+v want=%+v", mask, got, want)
 			}
 		})
@@ -166,7 +189,7 @@ func TestProp_X86Notes_LastRecordWins(t *testing.T) {
 		m2 := rapid.Uint32().Draw(t, "mask2")
 		data := append(buildPropertyNote(bo, GnuPropertyX86Feature1Flag, m1),
 			buildPropertyNote(bo, GnuPropertyX86Feature1Flag, m2)...)
-		if got, want := parseX86CETFromNotes(data, bo), parseBitmaskForx86CET(m2); got != want {
+		if got, want := parseX86CETFromNotes(data, bo, elf.ELFCLASS64), parseBitmaskForx86CET(m2); got != want {
 			t.Fatalf("m1=%#x m2=%#x got=%+v want=%+v", m1, m2, got, want)
 		}
 	})
@@ -177,8 +200,8 @@ func TestProp_NoteParsers_NeverPanic(t *testing.T) {
 	for _, bo := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
 		rapid.Check(t, func(t *rapid.T) {
 			data := rapid.SliceOfN(rapid.Byte(), 0, 256).Draw(t, "data")
-			_ = parseX86CETFromNotes(data, bo)
-			_ = parseArmPACBTIFromNotes(data, bo)
+			_ = parseX86CETFromNotes(data, bo, elf.ELFCLASS64)
+			_ = parseArmPACBTIFromNotes(data, bo, elf.ELFCLASS64)
 		})
 	}
 }
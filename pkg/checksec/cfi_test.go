@@ -3,6 +3,7 @@ package checksec
 import (
 	"debug/elf"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
 )
@@ -39,7 +40,8 @@ func TestArmPACBTI(t *testing.T) {
 		{"no pac & bti", 1, armPACBTI{pac: false, bti: true}},
 		{"pac & no bti", 2, armPACBTI{pac: true, bti: false}},
 		{"pac & bti", 3, armPACBTI{pac: true, bti: true}},
-		{"additional bits set", 0xFFFFFFFF, armPACBTI{pac: true, bti: true}},
+		{"gcs", 4, armPACBTI{gcs: true}},
+		{"additional bits set", 0xFFFFFFFF, armPACBTI{pac: true, bti: true, gcs: true}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -51,6 +53,64 @@ func TestArmPACBTI(t *testing.T) {
 	}
 }
 
+func TestCfi_I386(t *testing.T) {
+	tempDir := t.TempDir()
+	src := filepath.Join(tempDir, "main.c")
+	obj := filepath.Join(tempDir, "main.o")
+
+	if err := os.WriteFile(src, []byte("int main(void) { return 0; }\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	cmd := exec.Command("gcc", "-m32", "-fcf-protection=full", "-c", "-o", obj, src)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("cannot build i386 test ELF: %v (%s)", err, out)
+	}
+
+	res, err := Cfi(obj)
+	if err != nil {
+		t.Fatalf("Cfi() error = %v", err)
+	}
+	if res.Output != "SHSTK & IBT" || res.Status != StatusPass {
+		t.Errorf("Cfi() = %q/%q, want SHSTK & IBT/pass", res.Output, res.Status)
+	}
+}
+
+func TestCfi_X86_64(t *testing.T) {
+	// The property array is the descriptor of the note, not the raw
+	// .note.gnu.property section, which starts with the note header.
+	tests := []struct {
+		flag   string
+		output string
+		status Status
+	}{
+		{"-fcf-protection=full", "SHSTK & IBT", StatusPass},
+		{"-fcf-protection=branch", "NO SHSTK & IBT", StatusPartial},
+		{"-fcf-protection=return", "SHSTK & NO IBT", StatusPartial},
+	}
+	tempDir := t.TempDir()
+	src := filepath.Join(tempDir, "main.c")
+	if err := os.WriteFile(src, []byte("int main(void) { return 0; }\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.flag, func(t *testing.T) {
+			// An object: linking drops the note unless the crt files have it.
+			bin := filepath.Join(tempDir, tt.flag+".o")
+			cmd := exec.Command("gcc", "-m64", tt.flag, "-c", "-o", bin, src)
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Skipf("cannot build x86-64 test ELF: %v (%s)", err, out)
+			}
+			res, err := Cfi(bin)
+			if err != nil {
+				t.Fatalf("Cfi() error = %v", err)
+			}
+			if res.Output != tt.output || res.Status != tt.status {
+				t.Errorf("Cfi() = %q/%q, want %s/%s", res.Output, res.Status, tt.output, tt.status)
+			}
+		})
+	}
+}
+
 func TestCfi_InputValidation(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -197,11 +257,12 @@ func TestParseBitmaskForArmPACBTI_EdgeCases(t *testing.T) {
 		expected armPACBTI
 	}{
 		{"zero", 0, armPACBTI{pac: false, bti: false}},
-		{"max uint32", 0xFFFFFFFF, armPACBTI{pac: true, bti: true}},
+		{"max uint32", 0xFFFFFFFF, armPACBTI{pac: true, bti: true, gcs: true}},
 		{"only pac", GnuPropertyArmFeaturePAC, armPACBTI{pac: true, bti: false}},
 		{"only bti", GnuPropertyArmFeatureBTI, armPACBTI{pac: false, bti: true}},
 		{"both flags", GnuPropertyArmFeaturePAC | GnuPropertyArmFeatureBTI, armPACBTI{pac: true, bti: true}},
-		{"other bits set", 0xFFFFFFFC, armPACBTI{pac: false, bti: false}},
+		{"only gcs", GnuPropertyArmFeatureGCS, armPACBTI{gcs: true}},
+		{"other bits set", 0xFFFFFFF8, armPACBTI{pac: false, bti: false}},
 	}
 
 	for _, tt := range tests {
@@ -215,11 +276,11 @@ func TestParseBitmaskForArmPACBTI_EdgeCases(t *testing.T) {
 }
 
 func TestResUnknown(t *testing.T) {
-	cfi := &CfiResult{}
+	cfi := &Result{}
 	resUnknown(cfi)
 
-	if cfi.Color != "yellow" {
-		t.Errorf("Expected color 'yellow', got '%s'", cfi.Color)
+	if cfi.Color() != "yellow" {
+		t.Errorf("Expected color 'yellow', got '%s'", cfi.Color())
 	}
 
 	if cfi.Output != "Unknown" {
@@ -244,6 +305,9 @@ func TestConstants(t *testing.T) {
 	if GnuPropertyArmFeaturePAC != 2 {
 		t.Errorf("Expected GnuPropertyArmFeaturePAC to be 2, got %d", GnuPropertyArmFeaturePAC)
 	}
+	if GnuPropertyArmFeatureGCS != 4 {
+		t.Errorf("Expected GnuPropertyArmFeatureGCS to be 4, got %d", GnuPropertyArmFeatureGCS)
+	}
 
 	// Test flag constants
 	if GnuPropertyArmFeature1Flag != 0xc0000000 {
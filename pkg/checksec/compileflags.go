@@ -0,0 +1,315 @@
+package checksec
+
+import (
+	"bytes"
+	"debug/dwarf"
+	"debug/elf"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Hardening options every C and C++ compilation unit is expected to use. They
+// are reported under these names when a unit lacks them.
+const (
+	FlagStackProtector = "-fstack-protector-strong"
+	FlagPIE            = "-fPIE"
+	FlagFortify        = "-D_FORTIFY_SOURCE"
+	FlagCFProtection   = "-fcf-protection"
+)
+
+// gccCommandLineSection holds the switches recorded by -frecord-gcc-switches
+// (GCC) or -frecord-command-line (Clang).
+const gccCommandLineSection = ".GCC.command.line"
+
+// attrAppleFlags is DW_AT_APPLE_flags, where Clang records the command line on
+// Darwin targets.
+const attrAppleFlags dwarf.Attr = 0x3fe2
+
+// cLanguages are the DW_AT_language values of C, C++ and Objective-C units.
+// Units in other languages, such as assembler, are not judged.
+var cLanguages = map[int64]bool{
+	0x01: true, // C89
+	0x02: true, // C
+	0x04: true, // C++
+	0x0c: true, // C99
+	0x10: true, // ObjC
+	0x11: true, // ObjC++
+	0x19: true, // C++03
+	0x1a: true, // C++11
+	0x1d: true, // C11
+	0x21: true, // C++14
+}
+
+// CompileUnit is a compilation unit whose recorded command line lacks
+// hardening options.
+type CompileUnit struct {
+	// Name is the source file of the unit, or the section the command line
+	// was read from when no DWARF is available.
+	Name string `json:"name"`
+	// CommandLine is the recorded producer string or command line.
+	CommandLine string `json:"commandLine"`
+	// Missing lists the hardening options the unit was compiled without,
+	// named by the Flag constants.
+	Missing []string `json:"missing"`
+	// Unrecorded lists the hardening options the command line does not
+	// mention, so that whether the unit has them is not known.
+	Unrecorded []string `json:"unrecorded,omitempty"`
+}
+
+// CompileFlags is the machine value of the compile flags check.
+type CompileFlags struct {
+	// Source is where the command lines were read from: "dwarf" or
+	// ".GCC.command.line".
+	Source string `json:"source"`
+	// Units is the number of units whose command line was judged.
+	Units int `json:"units"`
+	// Unhardened lists the units that lack hardening options.
+	Unhardened []CompileUnit `json:"unhardened,omitempty"`
+	// Unrecorded lists the other units whose command line does not mention
+	// some hardening options.
+	Unrecorded []CompileUnit `json:"unrecorded,omitempty"`
+}
+
+// String lists the unhardened units, then those with unrecorded options, for
+// example "foo.c: -fcf-protection (not recorded: -fPIE); bar.c: (not
+// recorded: -fstack-protector-strong)".
+func (f CompileFlags) String() string {
+	var units []string
+	for _, u := range append(slices.Clip(f.Unhardened), f.Unrecorded...) {
+		unit := u.Name + ":"
+		if len(u.Missing) > 0 {
+			unit += " " + strings.Join(u.Missing, " ")
+		}
+		if len(u.Unrecorded) > 0 {
+			unit += " (not recorded: " + strings.Join(u.Unrecorded, " ") + ")"
+		}
+		units = append(units, unit)
+	}
+	return strings.Join(units, "; ")
+}
+
+// recordedUnit is a compilation unit with its command line.
+type recordedUnit struct {
+	name        string
+	commandLine string
+}
+
+// dwarfUnits returns the C and C++ compilation units of b that recorded their
+// compiler switches in DW_AT_producer or DW_AT_APPLE_flags.
+func dwarfUnits(b *Binary) []recordedUnit {
+	d, err := b.DWARF()
+	if err != nil {
+		return nil
+	}
+	var units []recordedUnit
+	r := d.Reader()
+	for {
+		entry, err := r.Next()
+		if err != nil || entry == nil {
+			break
+		}
+		if entry.Tag != dwarf.TagCompileUnit && entry.Tag != dwarf.TagPartialUnit {
+			r.SkipChildren()
+			continue
+		}
+		r.SkipChildren()
+		lang, _ := entry.Val(dwarf.AttrLanguage).(int64)
+		if !cLanguages[lang] {
+			continue
+		}
+		name, _ := entry.Val(dwarf.AttrName).(string)
+		producer, _ := entry.Val(dwarf.AttrProducer).(string)
+		if flags, ok := entry.Val(attrAppleFlags).(string); ok {
+			producer += " " + flags
+		}
+		if !hasSwitches(producer) {
+			continue
+		}
+		units = append(units, recordedUnit{name: name, commandLine: producer})
+	}
+	return units
+}
+
+// commandLineUnits returns the command lines of the .GCC.command.line section
+// of b.
+func commandLineUnits(b *Binary) []recordedUnit {
+	data, err := b.SectionData(gccCommandLineSection)
+	if err != nil {
+		return nil
+	}
+	return parseCommandLines(data)
+}
+
+// parseCommandLines splits the contents of .GCC.command.line. Current
+// compilers store one producer-style line per unit, which the linker merges
+// into one string per distinct line; older GCC releases store each switch as a
+// separate string, which are joined into a single unit.
+func parseCommandLines(data []byte) []recordedUnit {
+	var lines, switches []string
+	for _, s := range bytes.Split(data, []byte{0}) {
+		line := strings.TrimSpace(string(s))
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "-"):
+			switches = append(switches, line)
+		default:
+			lines = append(lines, line)
+		}
+	}
+
+	var units []recordedUnit
+	for _, line := range lines {
+		if hasSwitches(line) {
+			units = append(units, recordedUnit{name: gccCommandLineSection, commandLine: line})
+		}
+	}
+	if len(switches) > 0 {
+		units = append(units, recordedUnit{name: gccCommandLineSection, commandLine: strings.Join(switches, " ")})
+	}
+	return units
+}
+
+// hasSwitches reports whether a producer string records any option, rather
+// than only the compiler version.
+func hasSwitches(commandLine string) bool {
+	for _, arg := range strings.Fields(commandLine) {
+		if strings.HasPrefix(arg, "-") {
+			return true
+		}
+	}
+	return false
+}
+
+// binaryCET reports whether b carries the x86 CET properties that
+// -fcf-protection enables. The linker only keeps them when every object has
+// them, so they stand for the units that do not record the option, such as
+// those of toolchains enabling it by default.
+func binaryCET(b *Binary) bool {
+	data, ok := gnuProperties(b)
+	if !ok {
+		return false
+	}
+	cet := parseX86CETFromNotes(data, b.File.ByteOrder, b.File.Class)
+	return cet.ibt || cet.shstk
+}
+
+// missingHardeningFlags returns the hardening options a command line lacks
+// (missing) and those it does not mention at all (unrecorded); later options
+// override earlier ones, as they do for the compiler. Compilers only record
+// the options they were given, so a toolchain default such as default PIE is
+// unrecorded: the binary cannot tell which unit had it. -fcf-protection is
+// the exception, taken from cet when not recorded. GCC never records
+// preprocessor options, so -D_FORTIFY_SOURCE is only required of command
+// lines that record some; -fcf-protection only applies to x86.
+func missingHardeningFlags(commandLine string, x86, cet bool) (missing, unrecorded []string) {
+	cf := cet
+	var ssp, pie, sspRecorded, pieRecorded, fortify, preprocessor bool
+	args := strings.Fields(commandLine)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		// -D and -U may be separate from their macro.
+		if (arg == "-D" || arg == "-U") && i+1 < len(args) {
+			i++
+			arg += args[i]
+		}
+		switch {
+		case arg == "-fstack-protector-strong", arg == "-fstack-protector-all":
+			ssp, sspRecorded = true, true
+		case arg == "-fstack-protector", arg == "-fstack-protector-explicit", arg == "-fno-stack-protector":
+			ssp, sspRecorded = false, true
+		case arg == "-fPIE", arg == "-fpie", arg == "-fPIC", arg == "-fpic":
+			pie, pieRecorded = true, true
+		case arg == "-fno-PIE", arg == "-fno-pie", arg == "-fno-PIC", arg == "-fno-pic":
+			pie, pieRecorded = false, true
+		case arg == "-fcf-protection=none":
+			cf = false
+		case arg == "-fcf-protection", strings.HasPrefix(arg, "-fcf-protection="):
+			cf = true
+		case strings.HasPrefix(arg, "-D"), strings.HasPrefix(arg, "-U"):
+			preprocessor = true
+			macro, value, _ := strings.Cut(arg[2:], "=")
+			if macro == "_FORTIFY_SOURCE" {
+				fortify = arg[1] == 'D' && value != "0"
+			}
+		}
+	}
+
+	switch {
+	case !sspRecorded:
+		unrecorded = append(unrecorded, FlagStackProtector)
+	case !ssp:
+		missing = append(missing, FlagStackProtector)
+	}
+	switch {
+	case !pieRecorded:
+		unrecorded = append(unrecorded, FlagPIE)
+	case !pie:
+		missing = append(missing, FlagPIE)
+	}
+	if preprocessor && !fortify {
+		missing = append(missing, FlagFortify)
+	}
+	if x86 && !cf {
+		missing = append(missing, FlagCFProtection)
+	}
+	return missing, unrecorded
+}
+
+// compileFlagsCheck judges the recorded command line of every C and C++
+// compilation unit of b, preferring DWARF over .GCC.command.line.
+func compileFlagsCheck(b *Binary) *Result {
+	flags := CompileFlags{Source: "dwarf"}
+	units := dwarfUnits(b)
+	if len(units) == 0 {
+		flags.Source = gccCommandLineSection
+		units = commandLineUnits(b)
+	}
+	if len(units) == 0 {
+		if _, err := b.DWARF(); err == nil {
+			return &Result{Status: StatusUnknown, Output: "No Flags Recorded"}
+		}
+		return &Result{Status: StatusNA, Output: "No Command Line"}
+	}
+
+	x86 := b.File.Machine == elf.EM_386 || b.File.Machine == elf.EM_X86_64
+	cet := binaryCET(b)
+	for _, u := range units {
+		missing, unrecorded := missingHardeningFlags(u.commandLine, x86, cet)
+		// Missing is an array in JSON even when only Unrecorded is set.
+		unit := CompileUnit{Name: u.name, CommandLine: u.commandLine, Missing: append([]string{}, missing...), Unrecorded: unrecorded}
+		switch {
+		case len(missing) > 0:
+			flags.Unhardened = append(flags.Unhardened, unit)
+		case len(unrecorded) > 0:
+			flags.Unrecorded = append(flags.Unrecorded, unit)
+		}
+	}
+	flags.Units = len(units)
+
+	res := &Result{Value: flags}
+	switch len(flags.Unhardened) {
+	case 0:
+		if len(flags.Unrecorded) > 0 {
+			res.Status = StatusUnknown
+			res.Output = fmt.Sprintf("%d/%d Units Not Recorded", len(flags.Unrecorded), flags.Units)
+			return res
+		}
+		res.Status = StatusPass
+		res.Output = "All Units Hardened"
+		return res
+	case flags.Units:
+		res.Status = StatusFail
+	default:
+		res.Status = StatusPartial
+	}
+	res.Output = fmt.Sprintf("%d/%d Units Unhardened", len(flags.Unhardened), flags.Units)
+	return res
+}
+
+// CompileFlagsOf returns the CompileFlags carried by a compile flags result,
+// or the zero value if no command line was recorded.
+func CompileFlagsOf(r Result) CompileFlags {
+	flags, _ := r.Value.(CompileFlags)
+	return flags
+}
@@ -0,0 +1,227 @@
+package checksec
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestMissingHardeningFlags(t *testing.T) {
+	tests := []struct {
+		name        string
+		commandLine string
+		x86         bool
+		cet         bool
+		want        []string
+		unrecorded  []string
+	}{
+		{
+			name:        "gcc producer hardened",
+			commandLine: "GNU C17 12.2.0 -mtune=generic -march=x86-64 -g -O2 -fstack-protector-strong -fPIE -fcf-protection=full",
+			x86:         true,
+		},
+		{
+			name:        "gcc producer without options",
+			commandLine: "GNU C17 12.2.0 -mtune=generic -march=x86-64 -g -O2",
+			x86:         true,
+			want:        []string{FlagCFProtection},
+			unrecorded:  []string{FlagStackProtector, FlagPIE},
+		},
+		{
+			name:        "cf-protection taken from the CET property",
+			commandLine: "GNU C17 12.2.0 -mtune=generic -march=x86-64 -g -O2",
+			x86:         true,
+			cet:         true,
+			unrecorded:  []string{FlagStackProtector, FlagPIE},
+		},
+		{
+			name:        "explicit options override the CET property",
+			commandLine: "GNU C17 12.2.0 -g -O2 -fno-stack-protector -fno-pie -fcf-protection=none",
+			x86:         true,
+			cet:         true,
+			want:        []string{FlagStackProtector, FlagPIE, FlagCFProtection},
+		},
+		{
+			name:        "cf-protection only judged on x86",
+			commandLine: "GNU C17 12.2.0 -g -O2 -fstack-protector-all -fpic",
+		},
+		{
+			name:        "later options override earlier ones",
+			commandLine: "GNU C++17 12.2.0 -fstack-protector-strong -fPIE -fcf-protection -fno-stack-protector -fno-PIE -fcf-protection=none",
+			x86:         true,
+			want:        []string{FlagStackProtector, FlagPIE, FlagCFProtection},
+		},
+		{
+			name:        "weaker stack protector",
+			commandLine: "GNU C17 12.2.0 -fstack-protector -fPIC",
+			want:        []string{FlagStackProtector},
+		},
+		{
+			name:        "pie not recorded",
+			commandLine: "GNU C17 12.2.0 -fstack-protector-strong",
+			unrecorded:  []string{FlagPIE},
+		},
+		{
+			name:        "full command line with fortify",
+			commandLine: "/usr/bin/clang -cc1 -D NDEBUG -D_FORTIFY_SOURCE=3 -fstack-protector-strong -fPIE",
+		},
+		{
+			name:        "full command line without fortify",
+			commandLine: "/usr/bin/clang -cc1 -DNDEBUG -fstack-protector-strong -fPIE",
+			want:        []string{FlagFortify},
+		},
+		{
+			name:        "fortify disabled",
+			commandLine: "/usr/bin/clang -cc1 -D_FORTIFY_SOURCE=2 -U_FORTIFY_SOURCE -fstack-protector-strong -fPIE",
+			want:        []string{FlagFortify},
+		},
+		{
+			name:        "fortify level zero",
+			commandLine: "/usr/bin/clang -cc1 -D_FORTIFY_SOURCE=0 -fstack-protector-strong -fPIE",
+			want:        []string{FlagFortify},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, unrecorded := missingHardeningFlags(tt.commandLine, tt.x86, tt.cet)
+			if !reflect.DeepEqual(got, tt.want) || !reflect.DeepEqual(unrecorded, tt.unrecorded) {
+				t.Errorf("missingHardeningFlags = %v, %v, want %v, %v", got, unrecorded, tt.want, tt.unrecorded)
+			}
+		})
+	}
+}
+
+func TestParseCommandLines(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{"empty", "", nil},
+		{"producer lines", "GNU C17 12.2.0 -O2\x00GNU C++17 12.2.0 -O2 -fPIE\x00", []string{"GNU C17 12.2.0 -O2", "GNU C++17 12.2.0 -O2 -fPIE"}},
+		{"version only", "clang version 16.0.6\x00", nil},
+		{"one switch per string", "-O2\x00-fPIE\x00-fstack-protector-strong\x00", []string{"-O2 -fPIE -fstack-protector-strong"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, u := range parseCommandLines([]byte(tt.data)) {
+				if u.name != gccCommandLineSection {
+					t.Errorf("unit name = %q, want %q", u.name, gccCommandLineSection)
+				}
+				got = append(got, u.commandLine)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseCommandLines = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileFlagsString(t *testing.T) {
+	flags := CompileFlags{Units: 4, Unhardened: []CompileUnit{
+		{Name: "a.c", Missing: []string{FlagPIE, FlagCFProtection}},
+		{Name: "b.c", Missing: []string{FlagStackProtector}, Unrecorded: []string{FlagPIE}},
+	}, Unrecorded: []CompileUnit{
+		{Name: "c.c", Unrecorded: []string{FlagStackProtector}},
+	}}
+	want := "a.c: -fPIE -fcf-protection; b.c: -fstack-protector-strong (not recorded: -fPIE); c.c: (not recorded: -fstack-protector-strong)"
+	if got := flags.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got := CompileFlagsOf(Result{}).String(); got != "" {
+		t.Errorf("String() of an empty result = %q, want empty", got)
+	}
+}
+
+func TestCompileFlagsCheck_NoCommandLine(t *testing.T) {
+	b, err := OpenBinary(requireFixture(t, "all"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	res := compileFlagsCheck(b)
+	if res.Status != StatusNA || res.Output != "No Command Line" {
+		t.Errorf("compileFlagsCheck = %+v, want N/A", res)
+	}
+}
+
+// buildUnits compiles each source with its options into an object and links
+// the objects with -pie, skipping the test when gcc cannot.
+func buildUnits(t *testing.T, sources map[string]string, options map[string][]string) *Binary {
+	t.Helper()
+	tempDir := t.TempDir()
+	bin := filepath.Join(tempDir, "app")
+	link := []string{"-pie", "-o", bin}
+	for name, code := range sources {
+		src := filepath.Join(tempDir, name)
+		if err := os.WriteFile(src, []byte(code), 0o644); err != nil {
+			t.Fatalf("write source: %v", err)
+		}
+		obj := src + ".o"
+		args := append([]string{"-g", "-c", "-o", obj, src}, options[name]...)
+		if out, err := exec.Command("gcc", args...).CombinedOutput(); err != nil {
+			t.Skipf("cannot build test object: %v (%s)", err, out)
+		}
+		link = append(link, obj)
+	}
+	if out, err := exec.Command("gcc", link...).CombinedOutput(); err != nil {
+		t.Skipf("cannot link test ELF: %v (%s)", err, out)
+	}
+	b, err := OpenBinary(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { b.Close() })
+	return b
+}
+
+func TestCompileFlagsCheck_DefaultFlags(t *testing.T) {
+	// A plain -g build records no hardening options, whatever the toolchain
+	// enables by default.
+	b := buildUnits(t, map[string]string{
+		"main.c": "#include <stdio.h>\nint main(int argc, char **argv) { char b[64]; snprintf(b, sizeof b, \"%s\", argv[0]); return puts(b); }\n",
+	}, nil)
+
+	res := compileFlagsCheck(b)
+	flags := CompileFlagsOf(*res)
+	if res.Status == StatusPass {
+		t.Errorf("compileFlagsCheck = %s %q, want the unrecorded options reported", res.Status, res.Output)
+	}
+	for _, u := range append(flags.Unhardened, flags.Unrecorded...) {
+		if !reflect.DeepEqual(u.Unrecorded, []string{FlagStackProtector, FlagPIE}) {
+			t.Errorf("unit %s unrecorded = %v, want the stack protector and PIE", u.Name, u.Unrecorded)
+		}
+	}
+}
+
+func TestCompileFlagsCheck_OneUnitHardened(t *testing.T) {
+	// The binary has a canary and is PIE because of a.c alone; m.c must not
+	// be reported as hardened.
+	b := buildUnits(t, map[string]string{
+		"a.c": "#include <string.h>\nvoid copy(char *d, const char *s) { char b[64]; strcpy(b, s); strcpy(d, b); }\n",
+		"m.c": "void copy(char *, const char *);\nint main(int argc, char **argv) { char b[64]; copy(b, argv[0]); return b[0]; }\n",
+	}, map[string][]string{
+		"a.c": {"-fstack-protector-strong", "-fPIE", "-fcf-protection"},
+	})
+
+	res := compileFlagsCheck(b)
+	if res.Status == StatusPass {
+		t.Fatalf("compileFlagsCheck = %s %q, want m.c reported", res.Status, res.Output)
+	}
+	flags := CompileFlagsOf(*res)
+	var reported []string
+	for _, u := range append(flags.Unhardened, flags.Unrecorded...) {
+		reported = append(reported, filepath.Base(u.Name))
+		if filepath.Base(u.Name) == "m.c" && !slices.Contains(append(u.Missing, u.Unrecorded...), FlagStackProtector) {
+			t.Errorf("m.c reported as %+v, want the stack protector missing or not recorded", u)
+		}
+	}
+	if !reflect.DeepEqual(reported, []string{"m.c"}) {
+		t.Errorf("reported units = %v, want [m.c]", reported)
+	}
+}
@@ -0,0 +1,109 @@
+package checksec
+
+import (
+	"bufio"
+	"debug/elf"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DefaultDangerousFunctions are the libc functions the dangerous check looks
+// for unless Scanner.DangerousFunctions overrides them: functions that cannot
+// be used safely, are easy to misuse or are a common source of injection or
+// predictable values.
+var DefaultDangerousFunctions = []string{
+	// Unbounded copies.
+	"gets", "getwd", "strcpy", "strcat", "stpcpy", "wcscpy", "wcscat", "sprintf", "vsprintf",
+	// Shell command execution.
+	"system", "popen",
+	// Predictable temporary file names.
+	"mktemp", "tmpnam", "tempnam",
+	// Predictable random numbers.
+	"rand", "random", "drand48",
+	// Hidden state.
+	"strtok",
+}
+
+// LoadFunctionList reads a list of function names, one per line. Blank lines
+// and lines starting with # are ignored.
+func LoadFunctionList(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading function list: %w", err)
+	}
+	defer f.Close()
+
+	var names []string
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		name := strings.TrimSpace(scanner.Text())
+		if name == "" || strings.HasPrefix(name, "#") {
+			continue
+		}
+		if strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("%s:%d: invalid function name %q", path, line, name)
+		}
+		names = append(names, name)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading function list: %w", err)
+	}
+	return names, nil
+}
+
+// importedFunctions returns the names of the functions b imports, sorted and
+// without duplicates. It walks the undefined symbols of .symtab, the imported
+// symbols and the DT_SYMTAB functions, like the canary check.
+func importedFunctions(b *Binary) []string {
+	var names []string
+	addUndefined := func(symbols []elf.Symbol) {
+		for _, s := range symbols {
+			if s.Section == elf.SHN_UNDEF && s.Name != "" {
+				name, _, _ := strings.Cut(s.Name, "@")
+				names = append(names, name)
+			}
+		}
+	}
+	if symbols, err := b.Symbols(); err == nil {
+		addUndefined(symbols)
+	}
+	if imported, err := b.ImportedSymbols(); err == nil {
+		for _, s := range imported {
+			names = append(names, s.Name)
+		}
+	}
+	if functions, err := b.DynamicFunctions(); err == nil {
+		addUndefined(functions)
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// dangerousCheck lists the imports of b that are on its deny list.
+func dangerousCheck(b *Binary) *Result {
+	deny := b.DangerousFunctions
+	if deny == nil {
+		deny = DefaultDangerousFunctions
+	}
+	found := []string{}
+	for _, name := range importedFunctions(b) {
+		if slices.Contains(deny, name) {
+			found = append(found, name)
+		}
+	}
+
+	res := &Result{Status: StatusPass, Output: strconv.Itoa(len(found)), Value: found}
+	if len(found) > 0 {
+		res.Status = StatusPartial
+	}
+	return res
+}
+
+// DangerousFunctionsOf returns the functions listed by a dangerous result.
+func DangerousFunctionsOf(r Result) []string {
+	found, _ := r.Value.([]string)
+	return found
+}
@@ -0,0 +1,80 @@
+package checksec
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestLoadFunctionList(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		errMsg  string
+	}{
+		{"names", "gets\nstrcpy\n", []string{"gets", "strcpy"}, ""},
+		{"comments and blank lines", "# unbounded\n  gets  \n\n#system\nstrcpy", []string{"gets", "strcpy"}, ""},
+		{"empty", "", nil, ""},
+		{"invalid name", "gets\nstr cpy\n", nil, ":2: invalid function name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "deny.txt")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := LoadFunctionList(path)
+			if tt.errMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+					t.Fatalf("LoadFunctionList error = %v, want %q", err, tt.errMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadFunctionList = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := LoadFunctionList(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("LoadFunctionList of a missing file succeeded")
+	}
+}
+
+func TestDangerousCheck_Fixtures(t *testing.T) {
+	tests := []struct {
+		fixture string
+		deny    []string
+		status  Status
+		want    []string
+	}{
+		{"none", nil, StatusPartial, []string{"strcpy"}},
+		{"rel.o", nil, StatusPartial, []string{"strcpy"}},
+		{"all", nil, StatusPass, []string{}},
+		{"none", []string{"gets"}, StatusPass, []string{}},
+		{"none", []string{"printf", "strcpy"}, StatusPartial, []string{"printf", "strcpy"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			b, err := OpenBinary(requireFixture(t, tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer b.Close()
+			b.DangerousFunctions = tt.deny
+
+			res := dangerousCheck(b)
+			got := DangerousFunctionsOf(*res)
+			if res.Status != tt.status || !reflect.DeepEqual(got, tt.want) || res.Output != strconv.Itoa(len(tt.want)) {
+				t.Errorf("dangerousCheck = %s %q %q, want %s %q", res.Status, res.Output, got, tt.status, tt.want)
+			}
+		})
+	}
+}
@@ -23,8 +23,8 @@ func TestCanary_FixtureFound(t *testing.T) {
 	if res.Output != "Canary Found" {
 		t.Errorf("Output = %q, want %q", res.Output, "Canary Found")
 	}
-	if res.Color != "green" {
-		t.Errorf("Color = %q, want %q", res.Color, "green")
+	if res.Color() != "green" {
+		t.Errorf("Color = %q, want %q", res.Color(), "green")
 	}
 }
 
@@ -40,8 +40,8 @@ func TestCanary_FixtureNoCanary(t *testing.T) {
 	if res.Output != "No Canary Found" {
 		t.Errorf("Output = %q, want %q", res.Output, "No Canary Found")
 	}
-	if res.Color != "red" {
-		t.Errorf("Color = %q, want %q", res.Color, "red")
+	if res.Color() != "red" {
+		t.Errorf("Color = %q, want %q", res.Color(), "red")
 	}
 }
 
@@ -57,8 +57,8 @@ func TestSafeStack_FixtureStripped(t *testing.T) {
 	if res.Output != "No SafeStack Found" {
 		t.Errorf("Output = %q, want %q", res.Output, "No SafeStack Found")
 	}
-	if res.Color != "red" {
-		t.Errorf("Color = %q, want %q", res.Color, "red")
+	if res.Color() != "red" {
+		t.Errorf("Color = %q, want %q", res.Color(), "red")
 	}
 }
 
@@ -73,8 +73,8 @@ func TestCfi_FixtureArm64(t *testing.T) {
 		t.Error("Cfi() Output is empty")
 	}
 	validColors := map[string]bool{"green": true, "yellow": true, "red": true}
-	if !validColors[res.Color] {
-		t.Errorf("unexpected Color = %q", res.Color)
+	if !validColors[res.Color()] {
+		t.Errorf("unexpected Color = %q", res.Color())
 	}
 }
 
@@ -89,8 +89,8 @@ func TestCfi_FixtureX86(t *testing.T) {
 		t.Error("Cfi() Output is empty")
 	}
 	validColors := map[string]bool{"green": true, "yellow": true, "red": true}
-	if !validColors[res.Color] {
-		t.Errorf("unexpected Color = %q", res.Color)
+	if !validColors[res.Color()] {
+		t.Errorf("unexpected Color = %q", res.Color())
 	}
 }
 
@@ -184,11 +184,52 @@ func TestFortify_FixtureLibcSupported(t *testing.T) {
 	if res == nil {
 		t.Fatal("Fortify() returned nil")
 	}
-	if res.LibcSupport != "Yes" {
-		t.Errorf("LibcSupport = %q, want %q", res.LibcSupport, "Yes")
+	if FortifyDetailsOf(*res).LibcSupport.Output != "Yes" {
+		t.Errorf("LibcSupport = %q, want %q", FortifyDetailsOf(*res).LibcSupport.Output, "Yes")
 	}
 	validOutputs := map[string]bool{"Yes": true, "No": true}
 	if !validOutputs[res.Output] {
 		t.Errorf("unexpected Output = %q", res.Output)
 	}
 }
+
+func TestCfi_FixtureCET(t *testing.T) {
+	// "cet" carries a GNU property note with both x86 feature bits; the
+	// properties follow the note header, which must be skipped.
+	bin := requireFixture(t, "cet")
+
+	res, err := Cfi(bin)
+	if err != nil {
+		t.Fatalf("Cfi() error = %v", err)
+	}
+	if res.Output != "SHSTK & IBT" || res.Status != StatusPass {
+		t.Errorf("Cfi() = %q/%q, want SHSTK & IBT/pass", res.Output, res.Status)
+	}
+}
+
+func TestCfi_FixtureCETWithoutSections(t *testing.T) {
+	// Without section headers the property note is only reachable through
+	// the PT_NOTE and PT_GNU_PROPERTY segments.
+	data, err := os.ReadFile(requireFixture(t, "cet"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elf.Class(data[elf.EI_CLASS]) != elf.ELFCLASS64 {
+		t.Skip("fixture is not ELFCLASS64")
+	}
+	// Clear e_shoff, e_shnum and e_shstrndx.
+	clear(data[0x28:0x30])
+	clear(data[0x3c:0x40])
+	bin := filepath.Join(t.TempDir(), "cet-nosections")
+	if err := os.WriteFile(bin, data, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := Cfi(bin)
+	if err != nil {
+		t.Fatalf("Cfi() error = %v", err)
+	}
+	if res.Output != "SHSTK & IBT" || res.Status != StatusPass {
+		t.Errorf("Cfi() = %q/%q, want SHSTK & IBT/pass", res.Output, res.Status)
+	}
+}
@@ -5,154 +5,226 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"sort"
-	"strconv"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/slimm609/checksec/v3/pkg/output"
 	uroot "github.com/u-root/u-root/pkg/ldd"
 )
 
-type fortify struct {
-	Output           string
-	Color            string
-	Fortifiable      string
-	Fortified        string
-	NoFortify        string
-	LibcSupport      string
-	LibcSupportColor string
-	NumLibcFunc      string
-	NumFileFunc      string
-}
-
-// Fortify reports FORTIFY_SOURCE coverage for the binary at name. The binary
-// argument is accepted for API symmetry with the other checks but is unused;
-// the file is re-opened by path internally. ldd may be a pre-resolved libc path,
-// or "" to resolve it automatically.
-func Fortify(name string, binary *elf.File, ldd string) (*fortify, error) {
-	_ = binary
-	// limit to only checks that can actually be foritifed
-	// https://github.com/gcc-mirror/gcc/blob/master/gcc/builtins.def#L1112
-	supportedFuncs := []string{"__memcpy_chk", "__memmove_chk", "__mempcpy_chk", "__memset_chk", "__stpcpy_chk", "__stpncpy_chk", "__strcat_chk", "__strcpy_chk", "__strncat_chk", "__strncpy_chk", "__snprintf_chk", "__sprintf_chk", "__vsnprintf_chk", "__vsprintf_chk", "__fprintf_chk", "__printf_chk", "__vfprintf_chk", "__vprintf_chk"}
-	sort.Strings(supportedFuncs)
-
-	if ldd == "" {
-		resolved, err := getLdd(name)
-		if err != nil {
-			return nil, err
-		}
-		ldd = resolved
-	}
+// FortifyDetails is the machine value of the FORTIFY_SOURCE check.
+type FortifyDetails struct {
+	// LibcSupport reports whether the libc provides any fortified functions.
+	LibcSupport Result `json:"libcSupport"`
+	Fortified   int    `json:"fortified"`
+	Fortifiable int    `json:"fortifiable"`
+	NoFortify   int    `json:"noFortify"`
+	NumLibcFunc int    `json:"numLibcFunc"`
+	NumFileFunc int    `json:"numFileFunc"`
+	// FortifiedFuncs lists the __*_chk functions the binary calls, such as
+	// "__memcpy_chk".
+	FortifiedFuncs []string `json:"fortifiedFuncs,omitempty"`
+	// UnfortifiedFuncs lists the functions the binary calls whose checked
+	// variant the libc provides, such as "memcpy".
+	UnfortifiedFuncs []string `json:"unfortifiedFuncs,omitempty"`
+	// LibcFuncs lists the fortifiable __*_chk functions the libc provides.
+	LibcFuncs []string `json:"libcFuncs,omitempty"`
+}
 
-	return fortifyWithLdd(name, ldd, supportedFuncs)
+// FortifyDetailsOf returns the FortifyDetails carried by a Fortify result, or
+// the zero value if the check did not run.
+func FortifyDetailsOf(r Result) FortifyDetails {
+	details, _ := r.Value.(FortifyDetails)
+	return details
 }
 
-// fortifyWithLdd runs the fortify analysis given a resolved libc path (ldd).
-func fortifyWithLdd(name, ldd string, supportedFuncs []string) (*fortify, error) {
-	res := fortify{}
-	var chkFuncLibs []string
-	var funcLibs []string
-	var fileFunc []string
-	checked := 0
-	total := 0
+// FortifyFilter adjusts the set of __*_chk functions the FORTIFY check takes
+// from the libc. Names may be given as "__memcpy_chk" or as "memcpy".
+type FortifyFilter struct {
+	// Allow, when not empty, limits the check to these functions.
+	Allow []string
+	// Deny excludes these functions from the check.
+	Deny []string
+}
 
-	if ldd == "none" || ldd == "unk" {
-		res.Output = "N/A"
-		res.Color = "unset"
-		res.Fortified = "0"
-		res.Fortifiable = "0"
-		res.LibcSupport = "N/A"
-		return &res, nil
+// LoadFortifyFilter reads a FortifyFilter from a function list as read by
+// LoadFunctionList. Names prefixed with ! are denied, all others allowed.
+func LoadFortifyFilter(path string) (*FortifyFilter, error) {
+	names, err := LoadFunctionList(path)
+	if err != nil {
+		return nil, err
+	}
+	filter := &FortifyFilter{}
+	for _, name := range names {
+		if denied, ok := strings.CutPrefix(name, "!"); ok {
+			filter.Deny = append(filter.Deny, denied)
+		} else {
+			filter.Allow = append(filter.Allow, name)
+		}
 	}
+	return filter, nil
+}
 
-	libcfile, err := os.Open(ldd)
-	if err != nil {
-		return nil, fmt.Errorf("error opening libc file: %w", err)
+// allows reports whether the filter keeps the function with the base name
+// base. A nil filter keeps every function.
+func (f *FortifyFilter) allows(base string) bool {
+	if f == nil {
+		return true
 	}
-	defer libcfile.Close()
-	libc, err := elf.NewFile(libcfile)
-	if err != nil {
-		return nil, fmt.Errorf("error parsing libc file: %w", err)
+	matches := func(names []string) bool {
+		for _, name := range names {
+			if name == base || fortifyBaseName(name) == base {
+				return true
+			}
+		}
+		return false
 	}
+	return (len(f.Allow) == 0 || matches(f.Allow)) && !matches(f.Deny)
+}
 
-	libcDynSymbols, err := libc.DynamicSymbols()
-	if err != nil {
-		libcDynSymbols, err = FunctionsFromSymbolTable(libcfile)
-		if err != nil {
-			return nil, fmt.Errorf("error getting dynamic symbols from libc file: %w", err)
+// apply returns the checked functions and base names the filter keeps.
+func (f *FortifyFilter) apply(chkFuncs, baseFuncs []string) (chk, base []string) {
+	for i := range chkFuncs {
+		if f.allows(baseFuncs[i]) {
+			chk = append(chk, chkFuncs[i])
+			base = append(base, baseFuncs[i])
 		}
 	}
+	return chk, base
+}
 
-	// Determine which fortifiable __*_chk functions the libc actually provides.
-	chkFuncLibs, funcLibs = fortifyLibcFuncs(libcDynSymbols, supportedFuncs)
+// isChkFunc reports whether name is a checked function such as
+// "__memcpy_chk".
+func isChkFunc(name string) bool {
+	return strings.HasPrefix(name, "__") && !strings.HasPrefix(name, "___") && strings.HasSuffix(name, "_chk")
+}
 
-	if len(chkFuncLibs) > 0 {
-		res.LibcSupport = "Yes"
-		res.LibcSupportColor = "green"
-		res.NumLibcFunc = strconv.Itoa(len(chkFuncLibs))
-	} else {
-		res.LibcSupport = "No"
-		res.LibcSupportColor = "red"
-		res.NumLibcFunc = "0"
+// fortifyBaseName returns the unprotected name of a checked function, such as
+// "memcpy" for "__memcpy_chk".
+func fortifyBaseName(name string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(name, "__"), "_chk")
+}
+
+// Fortify reports FORTIFY_SOURCE coverage for the binary at name. binary may
+// be the already parsed file, or nil to open name. ldd may be a pre-resolved
+// libc path, or "" to resolve it automatically.
+func Fortify(name string, binary *elf.File, ldd string) (*Result, error) {
+	if binary == nil {
+		b, err := OpenBinary(name)
+		if err != nil {
+			return nil, err
+		}
+		defer b.Close()
+		b.Libc = ldd
+		return fortifyCheck(b)
 	}
+	b := &Binary{Path: name, File: binary, Libc: ldd}
+	defer b.closeRaw()
+	return fortifyCheck(b)
+}
 
-	f, err := os.Open(name)
-	if err != nil {
-		return nil, fmt.Errorf("error opening ELF file: %w", err)
+// fortifyCheck reports FORTIFY_SOURCE coverage for b against b.Libc, resolving
+// the libc from b's dependencies when it is empty.
+func fortifyCheck(b *Binary) (*Result, error) {
+	if isStatic(b) {
+		return fortifyStatic(b)
+	}
+	ldd := b.Libc
+	if ldd == "" {
+		resolved, err := getLdd(b)
+		if err != nil {
+			return nil, err
+		}
+		ldd = resolved
+	}
+
+	return fortifyWithLdd(b, ldd)
+}
+
+// fortifyWithLdd runs the fortify analysis given a resolved libc path (ldd).
+func fortifyWithLdd(b *Binary, ldd string) (*Result, error) {
+	if ldd == "none" || ldd == "unk" {
+		return fortifyNA(), nil
 	}
-	defer f.Close()
 
-	file, err := elf.NewFile(f)
+	// Determine which __*_chk functions the libc actually provides.
+	chkFuncLibs, funcLibs, err := libcFortifyFuncs(ldd)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing ELF file: %w", err)
+		return nil, err
 	}
 
-	dynSymbols, err := file.DynamicSymbols()
+	dynSymbols, err := b.DynamicSymbols()
 	if err != nil {
-		dynSymbols, err = FunctionsFromSymbolTable(f)
+		dynSymbols, err = b.DynamicFunctions()
 		if err != nil {
 			return nil, fmt.Errorf("error getting dynamic symbols from ELF file: %w", err)
 		}
 	}
-
-	// Iterate through dynamic symbols and print their information
+	var fileFunc []string
 	for _, sym := range dynSymbols {
-		fileFunc = append(fileFunc, strings.Trim(sym.Name, "__"))
+		fileFunc = append(fileFunc, sym.Name)
 	}
+	return fortifyResult(b, chkFuncLibs, funcLibs, fileFunc), nil
+}
 
-	checked, total = computeFortifyCounts(chkFuncLibs, funcLibs, fileFunc)
+// fortifyNA is the result for binaries whose libc is unknown.
+func fortifyNA() *Result {
+	details := FortifyDetails{LibcSupport: Result{Status: StatusNA, Output: "N/A"}}
+	return &Result{Status: StatusNA, Output: "N/A", Value: details}
+}
 
-	if checked > 0 {
-		res.Output = "Yes"
-		res.Color = "green"
-		res.Fortified = strconv.Itoa(checked)
-		res.Fortifiable = strconv.Itoa(total)
-		res.NoFortify = strconv.Itoa(total - checked)
-		res.NumFileFunc = strconv.Itoa(len(dynSymbols))
-		return &res, nil
+// fortifyResult compares the functions of the binary (fileFunc) with the
+// checked functions of its libc after b.FortifyFilter.
+func fortifyResult(b *Binary, chkFuncLibs, funcLibs, fileFunc []string) *Result {
+	chkFuncLibs, funcLibs = b.FortifyFilter.apply(chkFuncLibs, funcLibs)
+	details := FortifyDetails{NumLibcFunc: len(chkFuncLibs), NumFileFunc: len(fileFunc)}
+	for _, name := range chkFuncLibs {
+		details.LibcFuncs = append(details.LibcFuncs, "__"+name)
+	}
+	slices.Sort(details.LibcFuncs)
+	if len(chkFuncLibs) > 0 {
+		details.LibcSupport = Result{Status: StatusPass, Output: "Yes", Value: true}
 	} else {
-		res.Output = "No"
-		res.Color = "red"
-		res.Fortified = strconv.Itoa(checked)
-		res.Fortifiable = strconv.Itoa(total)
-		res.NoFortify = strconv.Itoa(total - checked)
-		res.NumFileFunc = strconv.Itoa(len(dynSymbols))
-		return &res, nil
+		details.LibcSupport = Result{Status: StatusFail, Output: "No", Value: false}
 	}
 
+	var trimmed []string
+	for _, name := range fileFunc {
+		trimmed = append(trimmed, strings.TrimPrefix(name, "__"))
+	}
+	fortified, unfortified := matchFortifyFuncs(chkFuncLibs, funcLibs, trimmed)
+	for _, name := range fortified {
+		details.FortifiedFuncs = append(details.FortifiedFuncs, "__"+name)
+	}
+	details.UnfortifiedFuncs = unfortified
+
+	details.Fortified = len(fortified)
+	details.Fortifiable = len(fortified) + len(unfortified)
+	details.NoFortify = len(unfortified)
+	res := &Result{Status: StatusFail, Output: "No", Value: details}
+	if details.Fortified > 0 {
+		res.Status = StatusPass
+		res.Output = "Yes"
+	}
+	return res
 }
 
-// fortifyLibcFuncs extracts, from a libc's symbols, the fortifiable __*_chk
-// functions it provides (chkFuncs) and their unprotected base names (baseFuncs),
-// limited to the compiler-supported fortify functions.
-func fortifyLibcFuncs(libcSyms []elf.Symbol, supportedFuncs []string) (chkFuncs, baseFuncs []string) {
+// fortifyLibcFuncs extracts, from a libc's symbols, every __*_chk function it
+// provides (chkFuncs) and their unprotected base names (baseFuncs), like the
+// bash version's readelf scan. Versioned duplicates are reported once.
+func fortifyLibcFuncs(libcSyms []elf.Symbol) (chkFuncs, baseFuncs []string) {
 	for _, sym := range libcSyms {
-		if strings.HasPrefix(sym.Name, "__") && strings.HasSuffix(sym.Name, "_chk") {
-			if isInSlice(sym.Name, supportedFuncs) {
-				chkFuncs = append(chkFuncs, strings.Trim(sym.Name, "__"))
-				baseFuncs = append(baseFuncs, strings.Trim(strings.Trim(sym.Name, "__"), "_chk"))
-			}
+		if !isChkFunc(sym.Name) {
+			continue
 		}
+		chk := strings.TrimPrefix(sym.Name, "__")
+		if isInSlice(chk, chkFuncs) {
+			continue
+		}
+		chkFuncs = append(chkFuncs, chk)
+		baseFuncs = append(baseFuncs, fortifyBaseName(sym.Name))
 	}
 	return chkFuncs, baseFuncs
 }
@@ -161,38 +233,97 @@ func fortifyLibcFuncs(libcSyms []elf.Symbol, supportedFuncs []string) (chkFuncs,
 // functions present in the target binary. It returns the number of fortified
 // calls and the total fortifiable count (fortified plus fortifiable-but-unprotected).
 func computeFortifyCounts(chkFuncs, baseFuncs, fileFuncs []string) (fortified, fortifiable int) {
-	sort.Strings(chkFuncs)
-	sort.Strings(baseFuncs)
-	sort.Strings(fileFuncs)
+	checked, unchecked := matchFortifyFuncs(chkFuncs, baseFuncs, fileFuncs)
+	return len(checked), len(checked) + len(unchecked)
+}
 
+// matchFortifyFuncs returns the libc's checked functions the binary calls
+// (fortified) and the unprotected base functions it calls instead
+// (unfortified), each sorted and without duplicates.
+func matchFortifyFuncs(chkFuncs, baseFuncs, fileFuncs []string) (fortified, unfortified []string) {
 	for _, item := range chkFuncs {
 		if isInSlice(item, fileFuncs) {
-			fortified++
+			fortified = append(fortified, item)
 		}
 	}
-	fortifiable = fortified
 	for _, item := range baseFuncs {
 		if isInSlice(item, fileFuncs) {
-			fortifiable++
+			unfortified = append(unfortified, item)
 		}
 	}
-	return fortified, fortifiable
+	slices.Sort(fortified)
+	slices.Sort(unfortified)
+	return slices.Compact(fortified), slices.Compact(unfortified)
 }
 
-func getLdd(filename string) (string, error) {
-	dynamic := false
-	file, err := elf.Open(filename)
+// libcKey identifies a libc file version in libcCache.
+type libcKey struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// libcFuncs is the memoised result of libcFortifyFuncs for one libc.
+type libcFuncs struct {
+	once      sync.Once
+	chkFuncs  []string
+	baseFuncs []string
+	err       error
+}
+
+// libcCache holds the fortifiable functions of every libc seen so far; a
+// scan usually resolves the same libc for every binary.
+var libcCache sync.Map
+
+// libcFortifyFuncs returns the __*_chk functions provided by the libc at path and their unprotected base names. Results are cached per
+// libc file.
+func libcFortifyFuncs(path string) (chkFuncs, baseFuncs []string, err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening libc file: %w", err)
+	}
+	entry, _ := libcCache.LoadOrStore(libcKey{path, info.Size(), info.ModTime()}, &libcFuncs{})
+	funcs := entry.(*libcFuncs)
+	funcs.once.Do(func() {
+		funcs.chkFuncs, funcs.baseFuncs, funcs.err = readLibcFortifyFuncs(path)
+	})
+	return funcs.chkFuncs, funcs.baseFuncs, funcs.err
+}
+
+func readLibcFortifyFuncs(path string) (chkFuncs, baseFuncs []string, err error) {
+	libcfile, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening libc file: %w", err)
+	}
+	defer libcfile.Close()
+	libc, err := elf.NewFile(libcfile)
 	if err != nil {
-		return "", fmt.Errorf("error opening ELF file: %w", err)
+		return nil, nil, fmt.Errorf("error parsing libc file: %w", err)
 	}
-	defer file.Close()
-	for _, prog := range file.Progs {
+
+	libcDynSymbols, err := libc.DynamicSymbols()
+	if err != nil {
+		libcDynSymbols, err = FunctionsFromSymbolTable(libcfile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error getting dynamic symbols from libc file: %w", err)
+		}
+	}
+
+	chkFuncs, baseFuncs = fortifyLibcFuncs(libcDynSymbols)
+	return chkFuncs, baseFuncs, nil
+}
+
+// getLdd resolves the libc b links against: its path, "none" for binaries
+// without a dynamic segment, or "unk" when it cannot be found.
+func getLdd(b *Binary) (string, error) {
+	dynamic := false
+	for _, prog := range b.File.Progs {
 		if prog.Type == elf.PT_DYNAMIC {
 			dynamic = true
 		}
 	}
 
-	filename, _ = filepath.Abs(filename)
+	filename, _ := filepath.Abs(b.Path)
 
 	files, _ := uroot.FList(filename)
 	if dynamic && len(files) == 0 {
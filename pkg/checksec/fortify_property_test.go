@@ -9,7 +9,8 @@ import (
 	"pgregory.net/rapid"
 )
 
-// fortifySupported mirrors the compiler-fortifiable function list used by Fortify.
+// fortifySupported is the compiler-fortifiable function list Fortify used
+// before it took every __*_chk function from the libc.
 var fortifySupported = []string{
 	"__memcpy_chk", "__memmove_chk", "__mempcpy_chk", "__memset_chk",
 	"__stpcpy_chk", "__stpncpy_chk", "__strcat_chk", "__strcpy_chk",
@@ -34,9 +35,10 @@ func sameStringSet(a, b []string) bool {
 	return true
 }
 
-// fortifyLibcFuncs must (a) keep only supported __*_chk functions and (b) derive
-// the chk name and base name as the symbol minus its "__" prefix and "_chk"
-// suffix. Symbols that are unsupported or not __*_chk must be dropped.
+// fortifyLibcFuncs filtered by an allow list must (a) keep only allowed
+// __*_chk functions and (b) derive the chk name and base name as the symbol
+// minus its "__" prefix and "_chk" suffix. Symbols that are not allowed or not
+// __*_chk must be dropped.
 func TestProp_FortifyLibcFuncs_OracleAndFiltering(t *testing.T) {
 	rapid.Check(t, func(t *rapid.T) {
 		var syms []elf.Symbol
@@ -56,7 +58,8 @@ func TestProp_FortifyLibcFuncs_OracleAndFiltering(t *testing.T) {
 			}
 		}
 
-		chk, base := fortifyLibcFuncs(syms, fortifySupported)
+		filter := &FortifyFilter{Allow: fortifySupported}
+		chk, base := filter.apply(fortifyLibcFuncs(syms))
 		if !sameStringSet(chk, wantChk) {
 			t.Fatalf("chkFuncs=%v want set %v", chk, wantChk)
 		}
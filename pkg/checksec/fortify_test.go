@@ -2,6 +2,8 @@ package checksec
 
 import (
 	"debug/elf"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -17,17 +19,17 @@ func TestFortify_NoneDynamic(t *testing.T) {
 	if result.Output != "N/A" {
 		t.Errorf("Output = %q, want N/A for ldd=none", result.Output)
 	}
-	if result.Color != "unset" {
-		t.Errorf("Color = %q, want unset for ldd=none", result.Color)
+	if result.Status != StatusNA {
+		t.Errorf("Status = %q, want n/a for ldd=none", result.Status)
 	}
-	if result.Fortified != "0" {
-		t.Errorf("Fortified = %q, want 0", result.Fortified)
+	if FortifyDetailsOf(*result).Fortified != 0 {
+		t.Errorf("Fortified = %d, want 0", FortifyDetailsOf(*result).Fortified)
 	}
-	if result.Fortifiable != "0" {
-		t.Errorf("Fortifiable = %q, want 0", result.Fortifiable)
+	if FortifyDetailsOf(*result).Fortifiable != 0 {
+		t.Errorf("Fortifiable = %d, want 0", FortifyDetailsOf(*result).Fortifiable)
 	}
-	if result.LibcSupport != "N/A" {
-		t.Errorf("LibcSupport = %q, want N/A", result.LibcSupport)
+	if FortifyDetailsOf(*result).LibcSupport.Output != "N/A" {
+		t.Errorf("LibcSupport = %q, want N/A", FortifyDetailsOf(*result).LibcSupport.Output)
 	}
 }
 
@@ -43,7 +45,76 @@ func TestFortify_UnknownLDD(t *testing.T) {
 	if result.Output != "N/A" {
 		t.Errorf("Output = %q, want N/A for ldd=unk", result.Output)
 	}
-	if result.Color != "unset" {
-		t.Errorf("Color = %q, want unset for ldd=unk", result.Color)
+	if result.Status != StatusNA {
+		t.Errorf("Status = %q, want n/a for ldd=unk", result.Status)
+	}
+}
+
+func TestMatchFortifyFuncs(t *testing.T) {
+	chk := []string{"memcpy_chk", "printf_chk", "strcpy_chk"}
+	base := []string{"memcpy", "printf", "strcpy"}
+	file := []string{"printf_chk", "strcpy", "memcpy_chk", "puts", "strcpy"}
+
+	fortified, unfortified := matchFortifyFuncs(chk, base, file)
+	if want := []string{"memcpy_chk", "printf_chk"}; !sameStringSet(fortified, want) {
+		t.Errorf("fortified = %v, want %v", fortified, want)
+	}
+	if want := []string{"strcpy"}; !sameStringSet(unfortified, want) {
+		t.Errorf("unfortified = %v, want %v", unfortified, want)
+	}
+}
+
+func TestFortifyLibcFuncs_AllChkSymbols(t *testing.T) {
+	syms := []elf.Symbol{
+		{Name: "__memcpy_chk"}, {Name: "__read_chk"}, {Name: "__explicit_bzero_chk"},
+		{Name: "__read_chk"}, {Name: "__stack_chk_fail"}, {Name: "memcpy"},
+	}
+	chk, base := fortifyLibcFuncs(syms)
+	if want := []string{"memcpy_chk", "read_chk", "explicit_bzero_chk"}; !sameStringSet(chk, want) {
+		t.Errorf("chkFuncs = %v, want %v", chk, want)
+	}
+	if want := []string{"memcpy", "read", "explicit_bzero"}; !sameStringSet(base, want) {
+		t.Errorf("baseFuncs = %v, want %v", base, want)
+	}
+}
+
+func TestFortifyFilter(t *testing.T) {
+	chk := []string{"memcpy_chk", "read_chk", "poll_chk"}
+	base := []string{"memcpy", "read", "poll"}
+	tests := []struct {
+		name   string
+		filter *FortifyFilter
+		want   []string
+	}{
+		{"nil keeps all", nil, []string{"memcpy", "read", "poll"}},
+		{"allow by chk name", &FortifyFilter{Allow: []string{"__memcpy_chk", "__poll_chk"}}, []string{"memcpy", "poll"}},
+		{"allow by base name", &FortifyFilter{Allow: []string{"read"}}, []string{"read"}},
+		{"deny", &FortifyFilter{Deny: []string{"__read_chk"}}, []string{"memcpy", "poll"}},
+		{"deny wins", &FortifyFilter{Allow: []string{"read", "poll"}, Deny: []string{"poll"}}, []string{"read"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotChk, gotBase := tt.filter.apply(chk, base)
+			if !sameStringSet(gotBase, tt.want) || len(gotChk) != len(gotBase) {
+				t.Errorf("apply() = %v, %v, want base names %v", gotChk, gotBase, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFortifyFilter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fortify.list")
+	if err := os.WriteFile(path, []byte("# allowed\n__memcpy_chk\nread\n\n!__poll_chk\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	filter, err := LoadFortifyFilter(path)
+	if err != nil {
+		t.Fatalf("LoadFortifyFilter() error = %v", err)
+	}
+	if !sameStringSet(filter.Allow, []string{"__memcpy_chk", "read"}) || !sameStringSet(filter.Deny, []string{"__poll_chk"}) {
+		t.Errorf("LoadFortifyFilter() = %+v", filter)
+	}
+	if _, err := LoadFortifyFilter(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("LoadFortifyFilter() of a missing file succeeded")
 	}
 }
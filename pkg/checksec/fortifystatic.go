@@ -0,0 +1,545 @@
+package checksec
+
+import (
+	"debug/elf"
+	"encoding/binary"
+	"slices"
+	"strings"
+)
+
+// glibcChkFuncs are the checked functions of glibc up to 2.38. A static
+// binary does not list what its libc fortifies, so unless a libc is given its
+// calls are matched against these.
+var glibcChkFuncs = []string{
+	"__asprintf_chk", "__confstr_chk", "__dprintf_chk", "__explicit_bzero_chk", "__fdelt_chk",
+	"__fgets_chk", "__fgets_unlocked_chk", "__fgetws_chk", "__fgetws_unlocked_chk", "__fprintf_chk",
+	"__fread_chk", "__fread_unlocked_chk", "__fwprintf_chk", "__getcwd_chk", "__getdomainname_chk",
+	"__getgroups_chk", "__gethostname_chk", "__getlogin_r_chk", "__gets_chk", "__getwd_chk",
+	"__longjmp_chk", "__mbsnrtowcs_chk", "__mbsrtowcs_chk", "__mbstowcs_chk", "__memcpy_chk",
+	"__memmove_chk", "__mempcpy_chk", "__memset_chk", "__obstack_printf_chk", "__obstack_vprintf_chk",
+	"__poll_chk", "__ppoll_chk", "__pread64_chk", "__pread_chk", "__printf_chk",
+	"__ptsname_r_chk", "__read_chk", "__readlink_chk", "__readlinkat_chk", "__realpath_chk",
+	"__recv_chk", "__recvfrom_chk", "__snprintf_chk", "__sprintf_chk", "__stpcpy_chk",
+	"__stpncpy_chk", "__strcat_chk", "__strcpy_chk", "__strlcat_chk", "__strlcpy_chk",
+	"__strncat_chk", "__strncpy_chk", "__swprintf_chk", "__syslog_chk", "__ttyname_r_chk",
+	"__vasprintf_chk", "__vdprintf_chk", "__vfprintf_chk", "__vfwprintf_chk", "__vprintf_chk",
+	"__vsnprintf_chk", "__vsprintf_chk", "__vswprintf_chk", "__vsyslog_chk", "__vwprintf_chk",
+	"__wcpcpy_chk", "__wcpncpy_chk", "__wcrtomb_chk", "__wcscat_chk", "__wcscpy_chk",
+	"__wcslcat_chk", "__wcslcpy_chk", "__wcsncat_chk", "__wcsncpy_chk", "__wcsnrtombs_chk",
+	"__wcsrtombs_chk", "__wcstombs_chk", "__wctomb_chk", "__wmemcpy_chk", "__wmemmove_chk",
+	"__wmempcpy_chk", "__wmemset_chk", "__wprintf_chk",
+}
+
+// Relocation types of the IRELATIVE entries through which static binaries
+// call IFUNCs such as memcpy and strcpy.
+const (
+	rX86_64IRelative  = 37
+	r386IRelative     = 42
+	rAArch64IRelative = 1032
+)
+
+// isStatic reports whether b is an executable or shared object that loads no
+// shared library: it has neither PT_INTERP nor DT_NEEDED. Static-PIE
+// executables have a PT_DYNAMIC segment but neither of those.
+func isStatic(b *Binary) bool {
+	if b.File.Type != elf.ET_EXEC && b.File.Type != elf.ET_DYN {
+		return false
+	}
+	return !hasProg(b.File, elf.PT_INTERP) && len(b.DynValue(elf.DT_NEEDED)) == 0
+}
+
+// fortifyStatic reports FORTIFY_SOURCE coverage for a static binary, whose
+// libc is linked in. Only the functions the program's own code calls count;
+// those the libc calls internally do not. They are matched against the
+// checked functions of b.Libc when one is given, and otherwise against those
+// of glibc.
+func fortifyStatic(b *Binary) (*Result, error) {
+	code, ok := newStaticCode(b)
+	if !ok {
+		return fortifyNA(), nil
+	}
+	fileFunc, ok := code.programCalls()
+	if !ok {
+		return fortifyNA(), nil
+	}
+
+	if ldd := b.Libc; ldd != "" && ldd != "none" && ldd != "unk" {
+		chkFuncLibs, funcLibs, err := libcFortifyFuncs(ldd)
+		if err != nil {
+			return nil, err
+		}
+		return fortifyResult(b, chkFuncLibs, funcLibs, fileFunc), nil
+	}
+
+	var known []elf.Symbol
+	for _, name := range glibcChkFuncs {
+		known = append(known, elf.Symbol{Name: name})
+	}
+	chkFuncLibs, funcLibs := fortifyLibcFuncs(known)
+	res := fortifyResult(b, chkFuncLibs, funcLibs, fileFunc)
+	if !code.linksChkFunc() {
+		// Without any checked function linked in, nothing shows whether
+		// the libc provides them.
+		details := FortifyDetailsOf(*res)
+		details.LibcSupport = Result{Status: StatusUnknown, Output: "Unknown"}
+		res.Value = details
+	}
+	return res, nil
+}
+
+// staticFunc is a function defined in a static binary.
+type staticFunc struct {
+	addr, size uint64
+	// libc is true for the functions of the libc and the compiler runtime.
+	libc bool
+}
+
+// staticCode indexes the functions of a static binary by address, to tell
+// the program's code from the libc linked into it.
+type staticCode struct {
+	b *Binary
+	// funcs are the defined functions, sorted by address.
+	funcs []staticFunc
+	// names holds the names of the functions at each address.
+	names map[uint64][]string
+	// relocs maps the code addresses the relocations kept by
+	// -Wl,--emit-relocs apply to, to the functions they reference.
+	relocs map[uint64]uint64
+	// slots maps IRELATIVE GOT slots to their IFUNC, read on first use.
+	slots map[uint64]uint64
+}
+
+// newStaticCode indexes the functions of b from .symtab, and reports false
+// when b has none: finding the program's calls in a stripped binary is not
+// implemented.
+func newStaticCode(b *Binary) (*staticCode, bool) {
+	symbols, err := b.Symbols()
+	if err != nil {
+		return nil, false
+	}
+	c := &staticCode{b: b, names: make(map[uint64][]string)}
+	sizes := make(map[uint64]uint64)
+	// Local functions are grouped by the STT_FILE symbol of their object.
+	objects := make(map[uint64]int)
+	libcObjects := make(map[int]bool)
+	object := 0
+	for _, s := range symbols {
+		typ := elf.ST_TYPE(s.Info)
+		if typ == elf.STT_FILE {
+			object++
+			continue
+		}
+		if (typ != elf.STT_FUNC && typ != elf.STT_GNU_IFUNC) || s.Section == elf.SHN_UNDEF || s.Section >= elf.SHN_LORESERVE || s.Value == 0 {
+			continue
+		}
+		c.names[s.Value] = append(c.names[s.Value], s.Name)
+		sizes[s.Value] = max(sizes[s.Value], s.Size)
+		if elf.ST_BIND(s.Info) == elf.STB_LOCAL && object > 0 {
+			objects[s.Value] = object
+			if isLibcFunction(s.Name) {
+				libcObjects[object] = true
+			}
+		}
+	}
+	if len(c.names) == 0 {
+		return nil, false
+	}
+	for addr, names := range c.names {
+		libc := slices.ContainsFunc(names, isLibcFunction)
+		if object, ok := objects[addr]; ok && libcObjects[object] {
+			libc = true
+		}
+		c.funcs = append(c.funcs, staticFunc{addr: addr, size: sizes[addr], libc: libc})
+	}
+	slices.SortFunc(c.funcs, func(a, b staticFunc) int {
+		switch {
+		case a.addr < b.addr:
+			return -1
+		case a.addr > b.addr:
+			return 1
+		}
+		return 0
+	})
+	c.relocs = c.codeRelocations(symbols)
+	return c, true
+}
+
+// isLibcFunction reports whether a function named name belongs to the libc or
+// the compiler runtime rather than to the program. Their functions are named,
+// or aliased, in the namespace C reserves for the implementation: a leading
+// underscore, as in __memcpy_chk, _IO_printf or __libc_start_main. C++
+// functions are mangled with the same prefix, _Z, and belong to the program.
+// The unchecked functions FORTIFY replaces belong to the libc whatever their
+// name.
+func isLibcFunction(name string) bool {
+	if strings.HasPrefix(name, "_") && !strings.HasPrefix(name, "_Z") {
+		return true
+	}
+	return slices.Contains(glibcChkFuncs, "__"+name+"_chk")
+}
+
+// linksChkFunc reports whether any checked function is linked into the
+// binary, which shows that its libc provides them.
+func (c *staticCode) linksChkFunc() bool {
+	for _, names := range c.names {
+		if slices.ContainsFunc(names, isChkFunc) {
+			return true
+		}
+	}
+	return false
+}
+
+// funcAt returns the function starting at addr.
+func (c *staticCode) funcAt(addr uint64) (staticFunc, bool) {
+	i, found := slices.BinarySearchFunc(c.funcs, addr, func(f staticFunc, addr uint64) int {
+		switch {
+		case f.addr < addr:
+			return -1
+		case f.addr > addr:
+			return 1
+		}
+		return 0
+	})
+	if !found {
+		return staticFunc{}, false
+	}
+	return c.funcs[i], true
+}
+
+// addrOf returns the address of the function named name.
+func (c *staticCode) addrOf(name string) (uint64, bool) {
+	for addr, names := range c.names {
+		if slices.Contains(names, name) {
+			return addr, true
+		}
+	}
+	return 0, false
+}
+
+// crtbeginFuncs are the functions of GCC's crtbegin.o and crtbeginT.o.
+var crtbeginFuncs = []string{"deregister_tm_clones", "register_tm_clones", "__do_global_dtors_aux", "frame_dummy"}
+
+// programWindow returns the address range of the program's code in .text:
+// the linker places the program's objects after the C runtime's crtbegin and
+// before the libraries, the first of which starts with the first libc
+// function. It reports false when crtbegin is not found.
+func (c *staticCode) programWindow() (start, end uint64, ok bool) {
+	for _, name := range crtbeginFuncs {
+		if addr, found := c.addrOf(name); found {
+			f, _ := c.funcAt(addr)
+			start, ok = max(start, addr+max(f.size, 1)), true
+		}
+	}
+	if !ok {
+		return 0, 0, false
+	}
+	end = ^uint64(0)
+	for _, f := range c.funcs {
+		if f.addr >= start && f.libc {
+			end = f.addr
+			break
+		}
+	}
+	return start, end, true
+}
+
+// programCalls returns the names of the functions the program's code calls.
+// The program's code is main, the functions linked between crtbegin and the
+// libraries, and the functions they call directly that lie before the
+// libraries and are not libc's. Binaries without main, such as Go ones, have
+// no C runtime: all their functions but the libc's are the program's. Calls
+// are read from the relocations of the code when the link kept them
+// (-Wl,--emit-relocs), and otherwise decoded on x86 and AArch64. It reports
+// false when neither is possible.
+func (c *staticCode) programCalls() ([]string, bool) {
+	targets := c.relocTargets
+	if len(c.relocs) == 0 {
+		switch c.b.File.Machine {
+		case elf.EM_X86_64, elf.EM_386:
+			targets = c.x86Targets
+		case elf.EM_AARCH64:
+			targets = c.aarch64Targets
+		default:
+			return nil, false
+		}
+	}
+
+	start, end, window := c.programWindow()
+	if !window {
+		end = ^uint64(0)
+	}
+	var queue []staticFunc
+	seen := make(map[uint64]bool)
+	visit := func(f staticFunc) {
+		if !f.libc && f.addr < end && !seen[f.addr] {
+			seen[f.addr] = true
+			queue = append(queue, f)
+		}
+	}
+	main, hasMain := c.addrOf("main")
+	for _, f := range c.funcs {
+		if !hasMain || (window && f.addr >= start) || f.addr == main {
+			visit(f)
+		}
+	}
+
+	var names []string
+	for len(queue) > 0 {
+		f := queue[0]
+		queue = queue[1:]
+		if f.size == 0 {
+			continue
+		}
+		code, err := readVirtual(c.b.File, f.addr, f.size)
+		if err != nil {
+			continue
+		}
+		for _, target := range targets(f, code) {
+			for _, name := range c.names[target] {
+				if !slices.Contains(names, name) {
+					names = append(names, name)
+				}
+			}
+			if callee, ok := c.funcAt(target); ok {
+				visit(callee)
+			}
+		}
+	}
+	return names, true
+}
+
+// codeRelocations returns the functions referenced by the SHT_REL and
+// SHT_RELA relocations of executable sections, by the address they apply to.
+// symbols is .symtab, which the relocations index.
+func (c *staticCode) codeRelocations(symbols []elf.Symbol) map[uint64]uint64 {
+	is64 := c.b.File.Class == elf.ELFCLASS64
+	relocs := make(map[uint64]uint64)
+	for _, section := range c.b.File.Sections {
+		if section.Type != elf.SHT_REL && section.Type != elf.SHT_RELA {
+			continue
+		}
+		if int(section.Info) >= len(c.b.File.Sections) || c.b.File.Sections[section.Info].Flags&elf.SHF_EXECINSTR == 0 {
+			continue
+		}
+		if int(section.Link) >= len(c.b.File.Sections) || c.b.File.Sections[section.Link].Type != elf.SHT_SYMTAB {
+			continue
+		}
+		data, err := c.b.SectionData(section.Name)
+		if err != nil {
+			continue
+		}
+		ent := relocEntSize(is64, section.Type == elf.SHT_RELA)
+		for off := uint64(0); off+ent <= uint64(len(data)); off += ent {
+			var addr, sym uint64
+			if is64 {
+				addr = c.b.File.ByteOrder.Uint64(data[off:])
+				sym = c.b.File.ByteOrder.Uint64(data[off+8:]) >> 32
+			} else {
+				addr = uint64(c.b.File.ByteOrder.Uint32(data[off:]))
+				sym = uint64(c.b.File.ByteOrder.Uint32(data[off+4:])) >> 8
+			}
+			// Symbols omits the null symbol at index 0.
+			if sym == 0 || sym > uint64(len(symbols)) {
+				continue
+			}
+			if s := symbols[sym-1]; len(c.names[s.Value]) > 0 {
+				relocs[addr] = s.Value
+			}
+		}
+	}
+	return relocs
+}
+
+// relocTargets returns the functions referenced by the relocations of f.
+func (c *staticCode) relocTargets(f staticFunc, code []byte) []uint64 {
+	var targets []uint64
+	for addr, target := range c.relocs {
+		if addr >= f.addr && addr < f.addr+uint64(len(code)) {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// x86Targets returns the targets of the calls and jumps in code, the body of
+// f: "call rel32" and "jmp rel32", and on x86-64 "call *slot(%rip)" and
+// "jmp *slot(%rip)". Calls through a PLT stub or a GOT slot resolve to the
+// IFUNC the slot is relocated to. Instructions are not decoded, so every
+// byte is tried as an opcode; an operand that happens to look like a call
+// only counts if it lands exactly on a function.
+func (c *staticCode) x86Targets(f staticFunc, code []byte) []uint64 {
+	is64 := c.b.File.Machine == elf.EM_X86_64
+	var targets []uint64
+	for i := 0; i+5 <= len(code); i++ {
+		next := f.addr + uint64(i) + 5
+		switch {
+		case code[i] == 0xe8 || code[i] == 0xe9:
+			target := next + uint64(int64(int32(binary.LittleEndian.Uint32(code[i+1:]))))
+			if stub, ok := c.x86Stub(target); ok {
+				target = stub
+			}
+			targets = append(targets, target)
+		case is64 && code[i] == 0xff && (code[i+1] == 0x15 || code[i+1] == 0x25) && i+6 <= len(code):
+			slot := next + 1 + uint64(int64(int32(binary.LittleEndian.Uint32(code[i+2:]))))
+			if target, ok := c.slotTarget(slot); ok {
+				targets = append(targets, target)
+			}
+		}
+	}
+	return targets
+}
+
+// x86Stub returns the function a PLT stub at addr jumps to through its GOT
+// slot: "jmp *slot(%rip)" on x86-64 and "jmp *slot" on i386, possibly after
+// endbr and bnd prefixes.
+func (c *staticCode) x86Stub(addr uint64) (uint64, bool) {
+	if !c.inPLT(addr) {
+		return 0, false
+	}
+	stub, err := readVirtual(c.b.File, addr, 16)
+	if err != nil {
+		return 0, false
+	}
+	for i := 0; i+6 <= len(stub); i++ {
+		if stub[i] != 0xff || stub[i+1] != 0x25 {
+			continue
+		}
+		slot := uint64(binary.LittleEndian.Uint32(stub[i+2:]))
+		if c.b.File.Machine == elf.EM_X86_64 {
+			slot = addr + uint64(i) + 6 + uint64(int64(int32(slot)))
+		}
+		return c.slotTarget(slot)
+	}
+	return 0, false
+}
+
+// aarch64Targets returns the targets of the "bl" and "b" instructions in
+// code, the body of f. Branches to a PLT stub resolve to the IFUNC its slot
+// is relocated to.
+func (c *staticCode) aarch64Targets(f staticFunc, code []byte) []uint64 {
+	bo := c.b.File.ByteOrder
+	var targets []uint64
+	for i := 0; i+4 <= len(code); i += 4 {
+		insn := bo.Uint32(code[i:])
+		if insn&0x7c000000 != 0x14000000 {
+			continue
+		}
+		// imm26 counts words and is signed.
+		offset := int64(int32(insn<<6)>>6) * 4
+		target := f.addr + uint64(i) + uint64(offset)
+		if stub, ok := c.aarch64Stub(target); ok {
+			target = stub
+		}
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+// aarch64Stub returns the function a PLT stub at addr jumps to.
+func (c *staticCode) aarch64Stub(addr uint64) (uint64, bool) {
+	if !c.inPLT(addr) {
+		return 0, false
+	}
+	stub, err := readVirtual(c.b.File, addr, 8)
+	if err != nil {
+		return 0, false
+	}
+	bo := c.b.File.ByteOrder
+	slot, ok := aarch64StubSlot(addr, bo.Uint32(stub), bo.Uint32(stub[4:]))
+	if !ok {
+		return 0, false
+	}
+	return c.slotTarget(slot)
+}
+
+// aarch64StubSlot decodes the GOT slot of a PLT stub at addr from its first
+// two instructions, "adrp x16, slot" and "ldr x17, [x16, #:lo12:slot]".
+func aarch64StubSlot(addr uint64, adrp, ldr uint32) (uint64, bool) {
+	if adrp&0x9f00001f != 0x90000010 || ldr&0xffc003ff != 0xf9400211 {
+		return 0, false
+	}
+	// The signed 21-bit page offset is split into immhi and immlo.
+	page := int64(int32(((adrp>>5)&0x7ffff<<2|(adrp>>29)&3)<<11) >> 11)
+	return addr&^0xfff + uint64(page<<12) + uint64((ldr>>10)&0xfff)*8, true
+}
+
+// inPLT reports whether addr lies in a PLT section, where static binaries
+// keep the stubs that call IFUNCs.
+func (c *staticCode) inPLT(addr uint64) bool {
+	for _, section := range c.b.File.Sections {
+		if (strings.HasPrefix(section.Name, ".plt") || section.Name == ".iplt") && addr >= section.Addr && addr < section.Addr+section.Size {
+			return true
+		}
+	}
+	return false
+}
+
+// slotTarget returns the IFUNC whose resolver the IRELATIVE relocation of the
+// GOT slot at addr names.
+func (c *staticCode) slotTarget(addr uint64) (uint64, bool) {
+	if c.slots == nil {
+		c.slots = c.irelativeSlots()
+	}
+	target, ok := c.slots[addr]
+	return target, ok
+}
+
+// irelativeSlots maps the GOT slots of the IRELATIVE relocations of the
+// binary to the resolvers they name, which are the addresses of the IFUNCs.
+func (c *staticCode) irelativeSlots() map[uint64]uint64 {
+	is64 := c.b.File.Class == elf.ELFCLASS64
+	bo := c.b.File.ByteOrder
+	slots := make(map[uint64]uint64)
+	for _, section := range c.b.File.Sections {
+		if section.Type != elf.SHT_REL && section.Type != elf.SHT_RELA {
+			continue
+		}
+		data, err := c.b.SectionData(section.Name)
+		if err != nil {
+			continue
+		}
+		rela := section.Type == elf.SHT_RELA
+		ent := relocEntSize(is64, rela)
+		for off := uint64(0); off+ent <= uint64(len(data)); off += ent {
+			var slot, typ, resolver uint64
+			if is64 {
+				slot, typ = bo.Uint64(data[off:]), bo.Uint64(data[off+8:])&0xffffffff
+				if rela {
+					resolver = bo.Uint64(data[off+16:])
+				}
+			} else {
+				slot, typ = uint64(bo.Uint32(data[off:])), uint64(bo.Uint32(data[off+4:])&0xff)
+				if rela {
+					resolver = uint64(bo.Uint32(data[off+8:]))
+				}
+			}
+			if !c.isIRelative(typ) {
+				continue
+			}
+			if !rela {
+				// REL keeps the resolver in the slot itself.
+				value, err := readVirtual(c.b.File, slot, 4)
+				if err != nil {
+					continue
+				}
+				resolver = uint64(bo.Uint32(value))
+			}
+			slots[slot] = resolver
+		}
+	}
+	return slots
+}
+
+// isIRelative reports whether typ is the IRELATIVE relocation type of the
+// binary's machine.
+func (c *staticCode) isIRelative(typ uint64) bool {
+	switch c.b.File.Machine {
+	case elf.EM_X86_64:
+		return typ == rX86_64IRelative
+	case elf.EM_386:
+		return typ == r386IRelative
+	case elf.EM_AARCH64:
+		return typ == rAArch64IRelative
+	}
+	return false
+}
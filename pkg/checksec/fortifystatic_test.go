@@ -0,0 +1,203 @@
+package checksec
+
+import (
+	"debug/elf"
+	"encoding/binary"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestIsStatic_Fixtures(t *testing.T) {
+	for _, fixture := range []string{"all", "dso.so", "rel.o"} {
+		t.Run(fixture, func(t *testing.T) {
+			b, err := OpenBinary(requireFixture(t, fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer b.Close()
+			if isStatic(b) {
+				t.Errorf("isStatic(%s) = true, want false", fixture)
+			}
+		})
+	}
+}
+
+func TestFortifyStatic_GoBinary(t *testing.T) {
+	tempDir := t.TempDir()
+	src := filepath.Join(tempDir, "main.go")
+	bin := filepath.Join(tempDir, "app")
+
+	if err := os.WriteFile(src, []byte("package main\nfunc main(){}\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	cmd := exec.Command("go", "build", "-o", bin, src)
+	cmd.Env = append(os.Environ(), "GOOS=linux", "GOARCH=amd64", "CGO_ENABLED=0")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("cannot build linux test ELF: %v (%s)", err, out)
+	}
+
+	b, err := OpenBinary(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	if !isStatic(b) {
+		t.Fatal("isStatic() = false for a static Go binary")
+	}
+	res, err := fortifyCheck(b)
+	if err != nil {
+		t.Fatalf("fortifyCheck() error = %v", err)
+	}
+	details := FortifyDetailsOf(*res)
+	if res.Status != StatusFail || details.NumFileFunc == 0 || details.LibcSupport.Status != StatusUnknown {
+		t.Errorf("fortifyCheck() = %s %q %+v, want fail with linked functions and unknown libc support", res.Status, res.Output, details)
+	}
+}
+
+func TestFortifyStatic_Glibc(t *testing.T) {
+	tempDir := t.TempDir()
+	src := filepath.Join(tempDir, "main.c")
+	bin := filepath.Join(tempDir, "app")
+
+	code := "#include <stdio.h>\n#include <string.h>\nint main(int c, char **v) { char b[8]; strcpy(b, v[0]); printf(\"%d %s\\n\", c, b); return 0; }\n"
+	if err := os.WriteFile(src, []byte(code), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	cmd := exec.Command("gcc", "-static", "-O2", "-D_FORTIFY_SOURCE=2", "-o", bin, src)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("cannot build static glibc test ELF: %v (%s)", err, out)
+	}
+
+	b, err := OpenBinary(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	res, err := fortifyCheck(b)
+	if err != nil {
+		t.Fatalf("fortifyCheck() error = %v", err)
+	}
+	details := FortifyDetailsOf(*res)
+	if res.Status != StatusPass || details.LibcSupport.Output != "Yes" {
+		t.Fatalf("fortifyCheck() = %s %q, libc support %q, want pass", res.Status, res.Output, details.LibcSupport.Output)
+	}
+	for _, want := range []string{"__printf_chk", "__strcpy_chk"} {
+		if !isInSlice(want, details.FortifiedFuncs) {
+			t.Errorf("FortifiedFuncs = %v, want %s", details.FortifiedFuncs, want)
+		}
+	}
+	// The unchecked functions the libc calls itself are not the program's.
+	if len(details.UnfortifiedFuncs) != 0 {
+		t.Errorf("UnfortifiedFuncs = %v, want none", details.UnfortifiedFuncs)
+	}
+}
+
+func TestFortifyStatic_GlibcUnfortified(t *testing.T) {
+	tempDir := t.TempDir()
+	src := filepath.Join(tempDir, "main.c")
+	bin := filepath.Join(tempDir, "app")
+
+	code := "#include <stdio.h>\n#include <string.h>\nint main(int c, char **v) { char b[8]; strcpy(b, v[0]); printf(\"%d %s\\n\", c, b); return 0; }\n"
+	if err := os.WriteFile(src, []byte(code), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	cmd := exec.Command("gcc", "-static", "-O2", "-U_FORTIFY_SOURCE", "-o", bin, src)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("cannot build static glibc test ELF: %v (%s)", err, out)
+	}
+
+	b, err := OpenBinary(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	res, err := fortifyCheck(b)
+	if err != nil {
+		t.Fatalf("fortifyCheck() error = %v", err)
+	}
+	details := FortifyDetailsOf(*res)
+	if res.Status != StatusFail || details.Fortified != 0 {
+		t.Fatalf("fortifyCheck() = %s %q with %d fortified, want fail", res.Status, res.Output, details.Fortified)
+	}
+	for _, want := range []string{"printf", "strcpy"} {
+		if !isInSlice(want, details.UnfortifiedFuncs) {
+			t.Errorf("UnfortifiedFuncs = %v, want %s", details.UnfortifiedFuncs, want)
+		}
+	}
+}
+
+func TestFortifyStatic_Stripped(t *testing.T) {
+	tempDir := t.TempDir()
+	src := filepath.Join(tempDir, "main.c")
+	bin := filepath.Join(tempDir, "app")
+
+	if err := os.WriteFile(src, []byte("#include <stdio.h>\nint main(void) { return puts(\"hi\"); }\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	cmd := exec.Command("gcc", "-static", "-s", "-O2", "-o", bin, src)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("cannot build static glibc test ELF: %v (%s)", err, out)
+	}
+
+	b, err := OpenBinary(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	res, err := fortifyCheck(b)
+	if err != nil {
+		t.Fatalf("fortifyCheck() error = %v", err)
+	}
+	if res.Status != StatusNA {
+		t.Errorf("fortifyCheck() = %s %q, want N/A", res.Status, res.Output)
+	}
+}
+
+func TestIsLibcFunction(t *testing.T) {
+	tests := map[string]bool{
+		"__libc_start_main": true,
+		"_IO_printf":        true,
+		"__memcpy_chk":      true,
+		"memcpy":            true,
+		"sprintf":           true,
+		"main":              false,
+		"helper":            false,
+		"_ZN3foo3barEv":     false,
+	}
+	for name, want := range tests {
+		if got := isLibcFunction(name); got != want {
+			t.Errorf("isLibcFunction(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestStaticCode_Targets(t *testing.T) {
+	le := binary.LittleEndian
+	x86 := &staticCode{b: &Binary{File: &elf.File{FileHeader: elf.FileHeader{Machine: elf.EM_X86_64, Class: elf.ELFCLASS64, ByteOrder: le}}}}
+	// call 0x1100; jmp 0x0f00
+	code := []byte{0xe8, 0xfb, 0x00, 0x00, 0x00, 0xe9, 0xf6, 0xfe, 0xff, 0xff}
+	if got := x86.x86Targets(staticFunc{addr: 0x1000}, code); !reflect.DeepEqual(got, []uint64{0x1100, 0x0f00}) {
+		t.Errorf("x86Targets = %#x, want [0x1100 0xf00]", got)
+	}
+
+	arm := &staticCode{b: &Binary{File: &elf.File{FileHeader: elf.FileHeader{Machine: elf.EM_AARCH64, Class: elf.ELFCLASS64, ByteOrder: le}}}}
+	// nop; bl 0x0ffc; b 0x1010; add x0, x0, #1
+	code = le.AppendUint32(le.AppendUint32(le.AppendUint32(le.AppendUint32(nil, 0xd503201f), 0x97fffffe), 0x14000002), 0x91000400)
+	if got := arm.aarch64Targets(staticFunc{addr: 0x1000}, code); !reflect.DeepEqual(got, []uint64{0x0ffc, 0x1010}) {
+		t.Errorf("aarch64Targets = %#x, want [0xffc 0x1010]", got)
+	}
+}
+
+func TestAArch64StubSlot(t *testing.T) {
+	// adrp x16, 0x4a0000; ldr x17, [x16, #0x18]
+	if slot, ok := aarch64StubSlot(0x400100, 0x90000510, 0xf9400e11); !ok || slot != 0x4a0018 {
+		t.Errorf("aarch64StubSlot = %#x, %v, want 0x4a0018", slot, ok)
+	}
+	// adrp x0 is not a PLT stub.
+	if _, ok := aarch64StubSlot(0x400100, 0x90000500, 0xf9400e11); ok {
+		t.Error("aarch64StubSlot accepted adrp x0")
+	}
+}
@@ -5,22 +5,31 @@ import (
 	"compress/gzip"
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"strings"
 
 	"github.com/opencontainers/selinux/go-selinux"
-	"github.com/slimm609/checksec/v3/pkg/output"
 )
 
-func KernelConfig(name string) ([]interface{}, []interface{}) {
-	var Results []interface{}
-	var ColorResults []interface{}
-	var Secolor string
-	var SelinuxStatus string
-	var Seres []interface{}
-	var Secolors []interface{}
+// KernelResult is the outcome of a single kernel config, sysctl or SELinux
+// check. Value is the human readable verdict, such as "Enabled".
+type KernelResult struct {
+	Name        string `json:"name"`
+	Description string `json:"desc"`
+	Type        string `json:"type"`
+	Value       string `json:"value"`
+	Status      Status `json:"status"`
+}
+
+// Color returns the presentation color for the result's status.
+func (r KernelResult) Color() string {
+	return r.Status.Color()
+}
 
+// KernelConfig checks the hardening options of the kernel config file name,
+// in a fixed order, followed by the SELinux status of the running system.
+// Options missing from the config are not reported.
+func KernelConfig(name string) ([]KernelResult, error) {
 	kernelChecks := []map[string]interface{}{
 		{"name": "CONFIG_COMPAT_BRK", "values": map[string]string{"arch": "all", "expect": "y", "desc": "Kernel Heap Randomization"}},
 		{"name": "CONFIG_STACKPROTECTOR", "values": map[string]string{"arch": "all", "expect": "y", "desc": "Stack Protector"}},
@@ -79,77 +88,28 @@ func KernelConfig(name string) ([]interface{}, []interface{}) {
 
 	data, err := parseKernelConfig(name)
 	if err != nil {
-		output.Fatalf("Error parsing kernel config: %v", err)
+		return nil, fmt.Errorf("error parsing kernel config: %w", err)
 	}
-	for configKey, configVal := range data {
-		for _, k := range kernelChecks {
-			var res []interface{}
-			var colors []interface{}
-			var output string
-			var color string
-			if k["name"] == configKey {
-				values := k["values"].(map[string]string)
-				if values["expect"] == configVal {
-					output = "Enabled"
-					color = "green"
-				} else {
-					output = "Disabled"
-					color = "red"
-				}
-				res = []interface{}{
-					map[string]interface{}{
-						"name":  k["name"],
-						"value": output,
-						"desc":  values["desc"],
-						"type":  "Kernel Config",
-					},
-				}
-				colors = []interface{}{
-					map[string]interface{}{
-						"name":  k["name"],
-						"value": output,
-						"color": color,
-						"desc":  values["desc"],
-						"type":  "Kernel Config",
-					},
-				}
-			}
-			Results = append(Results, res...)
-			ColorResults = append(ColorResults, colors...)
-
+	var results []KernelResult
+	for _, k := range kernelChecks {
+		name := k["name"].(string)
+		configVal, ok := data[name]
+		if !ok {
+			continue
 		}
+		values := k["values"].(map[string]string)
+		res := KernelResult{Name: name, Description: values["desc"], Type: "Kernel Config", Value: "Disabled", Status: StatusFail}
+		if values["expect"] == configVal {
+			res.Value, res.Status = "Enabled", StatusPass
+		}
+		results = append(results, res)
 	}
 
-	sestatus := selinux.GetEnabled()
-	if sestatus {
-		Secolor = "green"
-		SelinuxStatus = "Enabled"
-	} else {
-		Secolor = "red"
-		SelinuxStatus = "Disabled"
-	}
-
-	Seres = []interface{}{
-		map[string]interface{}{
-			"name":  "SELinux",
-			"value": SelinuxStatus,
-			"desc":  "SELinux Enabled",
-			"type":  "SELinux",
-		},
-	}
-	Secolors = []interface{}{
-		map[string]interface{}{
-			"name":  "SELinux",
-			"value": SelinuxStatus,
-			"color": Secolor,
-			"desc":  "SELinux Enabled",
-			"type":  "SELinux",
-		},
+	res := KernelResult{Name: "SELinux", Description: "SELinux Enabled", Type: "SELinux", Value: "Disabled", Status: StatusFail}
+	if selinux.GetEnabled() {
+		res.Value, res.Status = "Enabled", StatusPass
 	}
-	Results = append(Results, Seres...)
-	ColorResults = append(ColorResults, Secolors...)
-
-	return Results, ColorResults
+	return append(results, res), nil
 }
 
 func parseKernelConfig(filename string) (map[string]string, error) {
@@ -164,18 +124,18 @@ func parseKernelConfig(filename string) (map[string]string, error) {
 	if strings.HasSuffix(filename, ".gz") {
 		file, err := os.Open(filename)
 		if err != nil {
-			log.Fatal(err)
+			return nil, err
 		}
 		defer file.Close()
 		reader, err := gzip.NewReader(file)
 		if err != nil {
-			log.Fatal(err)
+			return nil, err
 		}
 		defer reader.Close()
 
 		bytes, err = io.ReadAll(reader)
 		if err != nil {
-			log.Fatal(err)
+			return nil, err
 		}
 	} else {
 		bytes, err = os.ReadFile(filename)
@@ -4,6 +4,7 @@ import (
 	"compress/gzip"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 )
 
@@ -13,29 +14,64 @@ func TestKernelConfig_ValidConfig(t *testing.T) {
 		t.Skipf("kernel.config fixture not found: %v", err)
 	}
 
-	results, colors := KernelConfig(fixture)
+	results, err := KernelConfig(fixture)
+	if err != nil {
+		t.Fatalf("KernelConfig: %v", err)
+	}
 
 	if len(results) == 0 {
 		t.Fatal("KernelConfig() returned no results")
 	}
-	if len(results) != len(colors) {
-		t.Fatalf("results length %d != colors length %d", len(results), len(colors))
-	}
 
 	for i, r := range results {
-		m, ok := r.(map[string]interface{})
-		if !ok {
-			t.Errorf("result[%d] is %T, want map[string]interface{}", i, r)
-			continue
+		if r.Name == "" || r.Description == "" || r.Type == "" || r.Value == "" {
+			t.Errorf("result[%d] has empty fields: %+v", i, r)
+		}
+		if r.Status != StatusPass && r.Status != StatusFail {
+			t.Errorf("result[%d] status = %q, want pass or fail", i, r.Status)
+		}
+	}
+
+}
+
+func TestKernelConfig_CheckOrder(t *testing.T) {
+	// The config lists the options in the reverse of the check list; the
+	// results follow the check list, so repeated runs print the same table.
+	config := filepath.Join(t.TempDir(), "config")
+	data := "CONFIG_CC_STACKPROTECTOR_STRONG=y\nCONFIG_STACKPROTECTOR_STRONG=y\nCONFIG_STACKPROTECTOR=y\nCONFIG_COMPAT_BRK=y\n"
+	if err := os.WriteFile(config, []byte(data), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	want := []string{"CONFIG_COMPAT_BRK", "CONFIG_STACKPROTECTOR", "CONFIG_STACKPROTECTOR_STRONG", "CONFIG_CC_STACKPROTECTOR_STRONG", "SELinux"}
+	for range 5 {
+		results, err := KernelConfig(config)
+		if err != nil {
+			t.Fatalf("KernelConfig: %v", err)
 		}
-		if len(m) == 0 {
-			t.Errorf("result[%d] map is empty", i)
+		var got []string
+		for _, r := range results {
+			got = append(got, r.Name)
+		}
+		if !slices.Equal(got, want) {
+			t.Fatalf("results = %v, want %v", got, want)
 		}
 	}
 }
 
 func TestKernelConfig_NonExistentFile(t *testing.T) {
-	t.Skip("KernelConfig calls os.Exit(1) on missing file — not testable without process isolation")
+	if _, err := KernelConfig(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Fatal("KernelConfig(missing) returned no error")
+	}
+}
+
+func TestKernelConfig_BadGzip(t *testing.T) {
+	gzPath := filepath.Join(t.TempDir(), "config.gz")
+	if err := os.WriteFile(gzPath, []byte("not gzip"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := KernelConfig(gzPath); err == nil {
+		t.Fatal("KernelConfig(bad gzip) returned no error")
+	}
 }
 
 func TestKernelConfig_GzippedConfig(t *testing.T) {
@@ -57,11 +93,18 @@ func TestKernelConfig_GzippedConfig(t *testing.T) {
 		t.Fatalf("write gzip: %v", err)
 	}
 
-	results, colors := KernelConfig(gzPath)
-	if len(results) != len(colors) {
-		t.Fatalf("results length %d != colors length %d", len(results), len(colors))
+	results, err := KernelConfig(gzPath)
+	if err != nil {
+		t.Fatalf("KernelConfig: %v", err)
 	}
-	if len(results) == 0 {
-		t.Error("expected non-empty results from gzipped config")
+	want := map[string]string{"CONFIG_STACKPROTECTOR": "Enabled", "CONFIG_DEVKMEM": "Disabled"}
+	for _, r := range results {
+		if v, ok := want[r.Name]; ok && r.Value != v {
+			t.Errorf("%s = %q, want %q", r.Name, r.Value, v)
+		}
+		delete(want, r.Name)
+	}
+	if len(want) != 0 {
+		t.Errorf("missing results for %v", want)
 	}
 }
@@ -0,0 +1,129 @@
+package checksec
+
+import (
+	"debug/elf"
+	"strings"
+)
+
+// ntAndroidTypeMemtag is the type of the Android note that requests memory
+// tagging, owned by "Android".
+const ntAndroidTypeMemtag = 4
+
+// androidLinker is the prefix of the interpreter of Android executables:
+// /system/bin/linker or /system/bin/linker64.
+const androidLinker = "/system/bin/linker"
+
+// Bits of the NT_ANDROID_TYPE_MEMTAG descriptor, as in bionic's elf.h: the
+// low two bits hold the level, the next two say what is tagged.
+const (
+	ntMemtagLevelMask  = 3
+	ntMemtagLevelNone  = 0
+	ntMemtagLevelAsync = 1
+	ntMemtagLevelSync  = 2
+	ntMemtagHeap       = 4
+	ntMemtagStack      = 8
+)
+
+// MTEMode is the machine value of the MTE check.
+type MTEMode struct {
+	// Level is the tag check mode requested: "none", "async" or "sync".
+	Level string `json:"level"`
+	// Heap is true when heap allocations are tagged.
+	Heap bool `json:"heap"`
+	// Stack is true when the binary was built with stack tagging.
+	Stack bool `json:"stack"`
+}
+
+// String describes the mode, for example "sync (heap, stack)".
+func (m MTEMode) String() string {
+	var tagged []string
+	if m.Heap {
+		tagged = append(tagged, "heap")
+	}
+	if m.Stack {
+		tagged = append(tagged, "stack")
+	}
+	if len(tagged) == 0 {
+		return m.Level
+	}
+	return m.Level + " (" + strings.Join(tagged, ", ") + ")"
+}
+
+// mteCheck reports the AArch64 Memory Tagging Extension mode b asks the
+// Android loader for in its NT_ANDROID_TYPE_MEMTAG note. Synchronous tag
+// checks pass; asynchronous ones report faults late and are partial. The note
+// is an Android convention, so the check does not apply to other binaries.
+func mteCheck(b *Binary) *Result {
+	if b.File.Machine != elf.EM_AARCH64 || !isAndroid(b) {
+		return &Result{Status: StatusNA, Output: "N/A"}
+	}
+	mode, ok := memtagMode(b)
+	if !ok {
+		return &Result{Status: StatusFail, Output: "No MTE"}
+	}
+	res := &Result{Output: "MTE " + mode.String(), Value: mode}
+	switch mode.Level {
+	case "sync":
+		res.Status = StatusPass
+	case "async":
+		res.Status = StatusPartial
+	case "none":
+		res.Status = StatusFail
+	default:
+		res.Status = StatusUnknown
+	}
+	return res
+}
+
+// memtagMode decodes the last NT_ANDROID_TYPE_MEMTAG note of b, and reports
+// whether there is one.
+func memtagMode(b *Binary) (MTEMode, bool) {
+	var mode MTEMode
+	found := false
+	for _, note := range b.Notes() {
+		if note.Name != "Android" || note.Type != ntAndroidTypeMemtag || len(note.Desc) < 4 {
+			continue
+		}
+		desc := b.File.ByteOrder.Uint32(note.Desc)
+		mode = MTEMode{Heap: desc&ntMemtagHeap != 0, Stack: desc&ntMemtagStack != 0}
+		switch desc & ntMemtagLevelMask {
+		case ntMemtagLevelNone:
+			mode.Level = "none"
+		case ntMemtagLevelAsync:
+			mode.Level = "async"
+		case ntMemtagLevelSync:
+			mode.Level = "sync"
+		default:
+			mode.Level = "unknown"
+		}
+		found = true
+	}
+	return mode, found
+}
+
+// isAndroid reports whether b was built for Android: it carries a note owned
+// by "Android", such as the NT_ANDROID_TYPE_IDENT note the NDK links into
+// every binary, or is loaded by the Android linker.
+func isAndroid(b *Binary) bool {
+	for _, note := range b.Notes() {
+		if note.Name == "Android" {
+			return true
+		}
+	}
+	for _, prog := range b.File.Progs {
+		if prog.Type != elf.PT_INTERP {
+			continue
+		}
+		interp := make([]byte, prog.Filesz)
+		if _, err := prog.ReadAt(interp, 0); err == nil && strings.HasPrefix(string(interp), androidLinker) {
+			return true
+		}
+	}
+	return false
+}
+
+// MTEModeOf returns the MTEMode carried by an MTE result.
+func MTEModeOf(r Result) MTEMode {
+	mode, _ := r.Value.(MTEMode)
+	return mode
+}
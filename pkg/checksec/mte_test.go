@@ -0,0 +1,52 @@
+package checksec
+
+import (
+	"debug/elf"
+	"encoding/binary"
+	"testing"
+)
+
+// memtagBinary returns a Binary for machine whose notes are notes.
+func memtagBinary(machine elf.Machine, notes ...Note) *Binary {
+	b := &Binary{File: &elf.File{FileHeader: elf.FileHeader{Machine: machine, ByteOrder: binary.LittleEndian}}}
+	_, _ = b.notes.get(func() ([]Note, error) { return notes, nil })
+	return b
+}
+
+func memtagNote(desc uint32) Note {
+	d := make([]byte, 4)
+	binary.LittleEndian.PutUint32(d, desc)
+	return Note{Name: "Android", Type: ntAndroidTypeMemtag, Desc: d}
+}
+
+// androidIdent is the NT_ANDROID_TYPE_IDENT note of NDK builds.
+var androidIdent = Note{Name: "Android", Type: 1, Desc: []byte{35, 0, 0, 0}}
+
+func TestMTECheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		b      *Binary
+		status Status
+		output string
+		mode   MTEMode
+	}{
+		{"not aarch64", memtagBinary(elf.EM_X86_64, memtagNote(ntMemtagLevelSync)), StatusNA, "N/A", MTEMode{}},
+		{"not android", memtagBinary(elf.EM_AARCH64, Note{Name: "GNU", Type: ntAndroidTypeMemtag, Desc: []byte{2, 0, 0, 0}}), StatusNA, "N/A", MTEMode{}},
+		{"android without note", memtagBinary(elf.EM_AARCH64, androidIdent), StatusFail, "No MTE", MTEMode{}},
+		{"none", memtagBinary(elf.EM_AARCH64, memtagNote(ntMemtagLevelNone)), StatusFail, "MTE none", MTEMode{Level: "none"}},
+		{"async heap", memtagBinary(elf.EM_AARCH64, memtagNote(ntMemtagLevelAsync|ntMemtagHeap)), StatusPartial, "MTE async (heap)", MTEMode{Level: "async", Heap: true}},
+		{"sync heap stack", memtagBinary(elf.EM_AARCH64, memtagNote(ntMemtagLevelSync|ntMemtagHeap|ntMemtagStack)), StatusPass, "MTE sync (heap, stack)", MTEMode{Level: "sync", Heap: true, Stack: true}},
+		{"reserved level", memtagBinary(elf.EM_AARCH64, memtagNote(3)), StatusUnknown, "MTE unknown", MTEMode{Level: "unknown"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := mteCheck(tt.b)
+			if res.Status != tt.status || res.Output != tt.output {
+				t.Errorf("mteCheck = %s %q, want %s %q", res.Status, res.Output, tt.status, tt.output)
+			}
+			if mode := MTEModeOf(*res); mode != tt.mode {
+				t.Errorf("mode = %+v, want %+v", mode, tt.mode)
+			}
+		})
+	}
+}
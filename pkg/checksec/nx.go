@@ -5,19 +5,14 @@ import (
 	"path/filepath"
 )
 
-type nx struct {
-	Output string
-	Color  string
-}
-
 // NX analyzes the NX (No eXecute) bit status of an ELF binary
 // It checks for the presence of PT_GNU_STACK segment and whether it has execute permissions
-func NX(name string, binary *elf.File) *nx {
-	res := nx{}
+func NX(name string, binary *elf.File) *Result {
+	res := Result{}
 
 	// Input validation - follow security rule: "ALWAYS validate input before processing"
 	if binary == nil {
-		res.Color = "red"
+		res.Status = StatusError
 		res.Output = "Error: Invalid binary"
 		return &res
 	}
@@ -27,7 +22,7 @@ func NX(name string, binary *elf.File) *nx {
 
 	// Check if binary has program headers
 	if len(binary.Progs) == 0 {
-		res.Color = "italic"
+		res.Status = StatusNA
 		res.Output = "N/A"
 		return &res
 	}
@@ -38,26 +33,92 @@ func NX(name string, binary *elf.File) *nx {
 		// Bounds checking - ensure we don't exceed reasonable limits
 		// Follow security rule: "ALWAYS implement resource limits to prevent DoS"
 		if i > 10000 { // Reasonable limit on program headers to prevent DoS
-			res.Color = "red"
+			res.Status = StatusError
 			res.Output = "Error: Too many program headers"
 			return &res
 		}
 
 		// Additional validation - ensure program header is not nil
-		if p == nil {
-			continue // Skip nil program headers gracefully
+		if p == nil || p.Type != elf.PT_GNU_STACK {
+			continue // Skip nil and unrelated program headers gracefully
 		}
 
-		// Check for GNU_STACK segment without execute permission
-		if p.Type == elf.PT_GNU_STACK && p.Flags&elf.PF_X == 0 {
-			res.Color = "green"
+		// GNU_STACK states explicitly whether the stack is executable
+		if p.Flags&elf.PF_X == 0 {
+			res.Status = StatusPass
 			res.Output = "NX enabled"
-			return &res
+			res.Value = true
+		} else {
+			res.Status = StatusFail
+			res.Output = "NX disabled"
+			res.Value = false
 		}
+		return &res
 	}
 
-	// If we reach here, either no GNU_STACK was found or it has execute permission
-	res.Color = "red"
-	res.Output = "NX disabled"
+	// Without GNU_STACK the kernel picks the architecture's default
+	switch defaultStack(binary) {
+	case stackNonExec:
+		res.Status = StatusPass
+		res.Output = "NX enabled by default"
+		res.Value = true
+	case stackExec:
+		res.Status = StatusFail
+		res.Output = "NX disabled by default"
+		res.Value = false
+	case stackExecWithoutRIXI:
+		res.Status = StatusPartial
+		res.Output = "NX depends on CPU"
+		res.Value = false
+	default:
+		// Assume the worst for architectures whose default is not known
+		res.Status = StatusFail
+		res.Output = "NX disabled"
+		res.Value = false
+	}
 	return &res
 }
+
+// nxCheck reports whether b asks for a non-executable stack.
+func nxCheck(b *Binary) *Result {
+	return NX(b.Path, b.File)
+}
+
+// stackDefault is how the kernel maps the stack of a binary without
+// PT_GNU_STACK.
+type stackDefault int
+
+const (
+	stackUnknown stackDefault = iota
+	// stackNonExec maps the stack non-executable.
+	stackNonExec
+	// stackExec sets READ_IMPLIES_EXEC, making the stack and every
+	// readable mapping executable.
+	stackExec
+	// stackExecWithoutRIXI is MIPS: READ_IMPLIES_EXEC is only set on CPUs
+	// without the RI/XI page protection bits.
+	stackExecWithoutRIXI
+)
+
+// defaultStack returns the kernel's default for binary at exec time, after
+// elf_read_implies_exec and VM_DATA_DEFAULT_FLAGS of each architecture.
+func defaultStack(binary *elf.File) stackDefault {
+	switch binary.Machine {
+	case elf.EM_386, elf.EM_ARM:
+		// Also when run by a 64-bit kernel in compat mode
+		return stackExec
+	case elf.EM_X86_64:
+		if binary.Class == elf.ELFCLASS32 {
+			// x32 executables are mapped like i386 ones
+			return stackExec
+		}
+		return stackNonExec
+	case elf.EM_PPC:
+		return stackExec
+	case elf.EM_AARCH64, elf.EM_PPC64, elf.EM_RISCV:
+		return stackNonExec
+	case elf.EM_MIPS, elf.EM_MIPS_RS3_LE:
+		return stackExecWithoutRIXI
+	}
+	return stackUnknown
+}
@@ -148,8 +148,8 @@ func TestNX(t *testing.T) {
 				t.Errorf("NX() Output = %q, expected %q", result.Output, tt.expectedOutput)
 			}
 
-			if result.Color != tt.expectedColor {
-				t.Errorf("NX() Color = %q, expected %q", result.Color, tt.expectedColor)
+			if result.Color() != tt.expectedColor {
+				t.Errorf("NX() Color = %q, expected %q", result.Color(), tt.expectedColor)
 			}
 
 			// Log test description for documentation
@@ -158,6 +158,51 @@ func TestNX(t *testing.T) {
 	}
 }
 
+// TestNX_ArchDefault tests the verdicts for binaries without GNU_STACK, whose
+// stack the kernel maps by the architecture's default
+func TestNX_ArchDefault(t *testing.T) {
+	tests := []struct {
+		machine elf.Machine
+		class   elf.Class
+		output  string
+		status  Status
+	}{
+		{elf.EM_X86_64, elf.ELFCLASS64, "NX enabled by default", StatusPass},
+		{elf.EM_X86_64, elf.ELFCLASS32, "NX disabled by default", StatusFail},
+		{elf.EM_386, elf.ELFCLASS32, "NX disabled by default", StatusFail},
+		{elf.EM_ARM, elf.ELFCLASS32, "NX disabled by default", StatusFail},
+		{elf.EM_AARCH64, elf.ELFCLASS64, "NX enabled by default", StatusPass},
+		{elf.EM_PPC, elf.ELFCLASS32, "NX disabled by default", StatusFail},
+		{elf.EM_PPC64, elf.ELFCLASS64, "NX enabled by default", StatusPass},
+		{elf.EM_RISCV, elf.ELFCLASS64, "NX enabled by default", StatusPass},
+		{elf.EM_MIPS, elf.ELFCLASS32, "NX depends on CPU", StatusPartial},
+		{elf.EM_SPARCV9, elf.ELFCLASS64, "NX disabled", StatusFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v_%v", tt.machine, tt.class), func(t *testing.T) {
+			mockBinary := createMockElfFile([]mockProgHeader{{progType: elf.PT_LOAD, flags: elf.PF_R | elf.PF_X}})
+			mockBinary.Machine = tt.machine
+			mockBinary.Class = tt.class
+
+			result := NX("/test/no_gnu_stack", mockBinary)
+			if result.Output != tt.output || result.Status != tt.status {
+				t.Errorf("NX() = %s %q, expected %s %q", result.Status, result.Output, tt.status, tt.output)
+			}
+		})
+	}
+
+	t.Run("explicit GNU_STACK wins", func(t *testing.T) {
+		mockBinary := createMockElfFile([]mockProgHeader{{progType: elf.PT_GNU_STACK, flags: elf.PF_R | elf.PF_W | elf.PF_X}})
+		mockBinary.Machine = elf.EM_X86_64
+		mockBinary.Class = elf.ELFCLASS64
+
+		if result := NX("/test/exec_stack", mockBinary); result.Output != "NX disabled" {
+			t.Errorf("NX() Output = %q, expected %q", result.Output, "NX disabled")
+		}
+	})
+}
+
 // TestNX_SecurityValidation tests security-specific edge cases
 func TestNX_SecurityValidation(t *testing.T) {
 	t.Run("nil binary handling", func(t *testing.T) {
@@ -175,8 +220,8 @@ func TestNX_SecurityValidation(t *testing.T) {
 			t.Errorf("Expected 'Error: Invalid binary' output for nil binary, got: %q", result.Output)
 		}
 
-		if result.Color != "red" {
-			t.Errorf("Expected 'red' color for nil binary error, got: %q", result.Color)
+		if result.Color() != "red" {
+			t.Errorf("Expected 'red' color for nil binary error, got: %q", result.Color())
 		}
 
 		t.Logf("SECURITY FIX VALIDATED: NX() handles nil input gracefully")
@@ -203,8 +248,8 @@ func TestNX_SecurityValidation(t *testing.T) {
 			t.Errorf("Expected DoS protection error, got: %q", result.Output)
 		}
 
-		if result.Color != "red" {
-			t.Errorf("Expected 'red' color for DoS protection error, got: %q", result.Color)
+		if result.Color() != "red" {
+			t.Errorf("Expected 'red' color for DoS protection error, got: %q", result.Color())
 		}
 
 		t.Logf("DoS PROTECTION VALIDATED: NX() limits program header processing")
@@ -261,11 +306,11 @@ func TestNX_EdgeCases(t *testing.T) {
 				t.Errorf("NX() returned empty output for: %s", tt.description)
 			}
 
-			if result.Color == "" {
+			if result.Color() == "" {
 				t.Errorf("NX() returned empty color for: %s", tt.description)
 			}
 
-			t.Logf("Edge case: %s - Result: %s (%s)", tt.description, result.Output, result.Color)
+			t.Logf("Edge case: %s - Result: %s (%s)", tt.description, result.Output, result.Color())
 		})
 	}
 }
@@ -323,8 +368,8 @@ func TestNX_ReturnValueValidation(t *testing.T) {
 				t.Errorf("Invalid output value: %q", result.Output)
 			}
 
-			if !validColors[result.Color] {
-				t.Errorf("Invalid color value: %q", result.Color)
+			if !validColors[result.Color()] {
+				t.Errorf("Invalid color value: %q", result.Color())
 			}
 		})
 	}
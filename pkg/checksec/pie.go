@@ -4,24 +4,43 @@ import (
 	"debug/elf"
 )
 
-type pie struct {
-	Output string
-	Color  string
+// PIE reports whether the binary is position independent.
+func PIE(name string, binary *elf.File) *Result {
+	b := &Binary{Path: name, File: binary}
+	defer b.closeRaw()
+	return pieCheck(b)
 }
 
-func PIE(name string, binary *elf.File) *pie {
-	res := pie{}
-	switch binary.Type {
+// pieCheck tells PIE executables, static-PIE executables and shared objects
+// apart. All of them are ET_DYN; shared objects are not executables, so the
+// check does not apply to them.
+func pieCheck(b *Binary) *Result {
+	switch b.File.Type {
 	case elf.ET_DYN:
-		res.Color = "green"
-		res.Output = "PIE Enabled"
+		switch {
+		case !isPIEExecutable(b):
+			return &Result{Status: StatusNA, Output: "DSO", Value: "dso"}
+		case hasProg(b.File, elf.PT_INTERP):
+			return &Result{Status: StatusPass, Output: "PIE", Value: "pie"}
+		}
+		return &Result{Status: StatusPass, Output: "Static PIE", Value: "static-pie"}
 	case elf.ET_REL:
-		res.Color = "yellow"
-		res.Output = "REL"
-	default:
-		res.Color = "red"
-		res.Output = "PIE Disabled"
+		return &Result{Status: StatusPartial, Output: "REL", Value: "rel"}
 	}
+	return &Result{Status: StatusFail, Output: "No PIE", Value: "none"}
+}
 
-	return &res
+// isPIEExecutable reports whether the ET_DYN file b is an executable rather
+// than a shared object: it has an interpreter, is flagged DF_1_PIE, or has a
+// DT_DEBUG entry, which linkers only emit for executables.
+func isPIEExecutable(b *Binary) bool {
+	if hasProg(b.File, elf.PT_INTERP) || len(b.DynValue(elf.DT_DEBUG)) > 0 {
+		return true
+	}
+	for _, flags := range b.DynValue(elf.DT_FLAGS_1) {
+		if elf.DynFlag1(flags)&elf.DF_1_PIE != 0 {
+			return true
+		}
+	}
+	return false
 }
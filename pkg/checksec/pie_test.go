@@ -2,6 +2,9 @@ package checksec
 
 import (
 	"debug/elf"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"testing"
 )
 
@@ -12,10 +15,10 @@ func TestPIE(t *testing.T) {
 		expectedOutput string
 		expectedColor  string
 	}{
-		{"ET_DYN is PIE enabled", elf.ET_DYN, "PIE Enabled", "green"},
+		{"ET_DYN without PIE markers is a DSO", elf.ET_DYN, "DSO", "italic"},
 		{"ET_REL is REL", elf.ET_REL, "REL", "yellow"},
-		{"ET_EXEC is PIE disabled", elf.ET_EXEC, "PIE Disabled", "red"},
-		{"ET_NONE is PIE disabled", elf.ET_NONE, "PIE Disabled", "red"},
+		{"ET_EXEC is not PIE", elf.ET_EXEC, "No PIE", "red"},
+		{"ET_NONE is not PIE", elf.ET_NONE, "No PIE", "red"},
 	}
 
 	for _, tt := range tests {
@@ -28,9 +31,61 @@ func TestPIE(t *testing.T) {
 			if result.Output != tt.expectedOutput {
 				t.Errorf("Output = %q, want %q", result.Output, tt.expectedOutput)
 			}
-			if result.Color != tt.expectedColor {
-				t.Errorf("Color = %q, want %q", result.Color, tt.expectedColor)
+			if result.Color() != tt.expectedColor {
+				t.Errorf("Color = %q, want %q", result.Color(), tt.expectedColor)
 			}
 		})
 	}
 }
+
+func TestPIE_Fixtures(t *testing.T) {
+	tests := []struct {
+		fixture string
+		status  Status
+		output  string
+	}{
+		{"all", StatusPass, "PIE"},
+		{"dso.so", StatusNA, "DSO"},
+		{"none", StatusFail, "No PIE"},
+		{"rel.o", StatusPartial, "REL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			b, err := OpenBinary(requireFixture(t, tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer b.Close()
+			res := pieCheck(b)
+			if res.Status != tt.status || res.Output != tt.output {
+				t.Errorf("pieCheck = %s %q, want %s %q", res.Status, res.Output, tt.status, tt.output)
+			}
+		})
+	}
+}
+
+func TestPIE_StaticPIE(t *testing.T) {
+	tempDir := t.TempDir()
+	src := filepath.Join(tempDir, "main.c")
+	bin := filepath.Join(tempDir, "app")
+
+	if err := os.WriteFile(src, []byte("int main(void) { return 0; }\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	cmd := exec.Command("gcc", "-static-pie", "-s", "-o", bin, src)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("cannot build static-pie test ELF: %v (%s)", err, out)
+	}
+
+	b, err := OpenBinary(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	if res := pieCheck(b); res.Status != StatusPass || res.Output != "Static PIE" {
+		t.Errorf("pieCheck = %s %q, want pass %q", res.Status, res.Output, "Static PIE")
+	}
+	if kind := binaryKind(b); kind != KindExecutable {
+		t.Errorf("binaryKind = %q, want %q", kind, KindExecutable)
+	}
+}
@@ -0,0 +1,165 @@
+package checksec
+
+import (
+	"debug/elf"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Binary kinds reported in FileReport.Kind and matched by policy rules.
+const (
+	// KindExecutable is an ET_EXEC file, or an ET_DYN file with an
+	// interpreter, the DF_1_PIE flag or DT_DEBUG (a PIE executable).
+	KindExecutable = "executable"
+	// KindShared is any other ET_DYN file, such as a shared library.
+	KindShared = "shared"
+	// KindRelocatable is an ET_REL file, such as an object file or a kernel
+	// module.
+	KindRelocatable = "relocatable"
+	// KindOther is any other ELF type, such as a core dump.
+	KindOther = "other"
+)
+
+// binaryKind classifies b for policy rules.
+func binaryKind(b *Binary) string {
+	switch b.File.Type {
+	case elf.ET_EXEC:
+		return KindExecutable
+	case elf.ET_DYN:
+		if isPIEExecutable(b) {
+			return KindExecutable
+		}
+		return KindShared
+	case elf.ET_REL:
+		return KindRelocatable
+	}
+	return KindOther
+}
+
+// Policy lists the mitigations binaries are required to have. For every
+// report the first rule whose path and kind match applies; reports that no
+// rule matches are not checked.
+type Policy struct {
+	Rules []PolicyRule `json:"rules"`
+}
+
+// PolicyRule requires the checks in Require to pass for the binaries it
+// matches. A rule without requirements exempts the binaries it matches from
+// later rules.
+type PolicyRule struct {
+	// Name identifies the rule in violations.
+	Name string `json:"name"`
+	// Path is a filepath.Match glob. A pattern without a separator is
+	// matched against the base name, so "*.so" matches libraries in any
+	// directory. Empty matches every path.
+	Path string `json:"path,omitempty"`
+	// Kind is one of the Kind constants. Empty matches every kind.
+	Kind string `json:"kind,omitempty"`
+	// Require lists the IDs of the checks that must pass. A check that does
+	// not apply to a binary is not a violation.
+	Require []string `json:"require,omitempty"`
+}
+
+// Violation is a required check that did not pass.
+type Violation struct {
+	// Path is the binary that violates the policy.
+	Path string `json:"path"`
+	// Rule is the name of the rule that required the check.
+	Rule string `json:"rule"`
+	// Check is the ID of the check.
+	Check string `json:"check"`
+	// Result is the result of the check.
+	Result Result `json:"result"`
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s is %q (required by rule %q)", v.Path, v.Check, v.Result.Output, v.Rule)
+}
+
+// LoadPolicy reads a YAML (or JSON) policy file and validates its rules
+// against the registered checks.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading policy: %w", err)
+	}
+	var p Policy
+	if err := yaml.UnmarshalStrict(data, &p); err != nil {
+		return nil, fmt.Errorf("parsing policy %s: %w", path, err)
+	}
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("policy %s: %w", path, err)
+	}
+	return &p, nil
+}
+
+// validate checks the globs, kinds and check IDs of every rule and names
+// unnamed rules after their position.
+func (p *Policy) validate() error {
+	ids := make(map[string]bool)
+	for _, c := range Checks() {
+		ids[c.ID()] = true
+	}
+	kinds := []string{"", KindExecutable, KindShared, KindRelocatable, KindOther}
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		if _, err := filepath.Match(rule.Path, ""); err != nil {
+			return fmt.Errorf("%s: invalid path %q: %w", rule.Name, rule.Path, err)
+		}
+		if !slices.Contains(kinds, rule.Kind) {
+			return fmt.Errorf("%s: unknown kind %q", rule.Name, rule.Kind)
+		}
+		for _, id := range rule.Require {
+			if !ids[id] {
+				return fmt.Errorf("%s: unknown check %q", rule.Name, id)
+			}
+		}
+	}
+	return nil
+}
+
+// matches reports whether the rule applies to report.
+func (r *PolicyRule) matches(report *FileReport) bool {
+	if r.Kind != "" && r.Kind != report.Kind {
+		return false
+	}
+	if r.Path == "" {
+		return true
+	}
+	name := report.Name
+	if !strings.ContainsRune(r.Path, filepath.Separator) {
+		name = filepath.Base(name)
+	}
+	ok, _ := filepath.Match(r.Path, name)
+	return ok
+}
+
+// Evaluate returns the violations of report. A nil Policy has none.
+func (p *Policy) Evaluate(report *FileReport) []Violation {
+	if p == nil {
+		return nil
+	}
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if !rule.matches(report) {
+			continue
+		}
+		var violations []Violation
+		for _, id := range rule.Require {
+			res, _ := report.Result(id)
+			if res.Status != StatusPass && res.Status != StatusNA {
+				violations = append(violations, Violation{Path: report.Name, Rule: rule.Name, Check: id, Result: res})
+			}
+		}
+		return violations
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package checksec
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePolicy(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	return path
+}
+
+func TestLoadPolicy_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"not yaml", "rules: [", "parsing policy"},
+		{"unknown field", "rules:\n  - requires: [relro]\n", "parsing policy"},
+		{"unknown kind", "rules:\n  - kind: program\n", `rule 1: unknown kind "program"`},
+		{"bad glob", "rules:\n  - name: libs\n    path: \"[\"\n", `libs: invalid path "["`},
+		{"unknown check", "rules:\n  - require: [relro, aslr]\n", `unknown check "aslr"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadPolicy(writePolicy(t, tt.content)); err == nil || !contains(err.Error(), tt.want) {
+				t.Errorf("LoadPolicy error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+	if _, err := LoadPolicy(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("LoadPolicy(missing) returned no error")
+	}
+}
+
+func TestPolicy_Evaluate(t *testing.T) {
+	policy, err := LoadPolicy(writePolicy(t, `
+rules:
+  - name: modules
+    path: "*.ko"
+  - name: executables
+    kind: executable
+    require: [relro, pie, canary]
+  - name: system libraries
+    path: /usr/lib/*.so
+    require: [nx, relro]
+  - name: libraries
+    kind: shared
+    require: [nx]
+`))
+	if err != nil {
+		t.Fatalf("LoadPolicy: %v", err)
+	}
+
+	pass := Result{Status: StatusPass, Output: "ok"}
+	fail := Result{Status: StatusFail, Output: "missing"}
+	report := func(name, kind string) *FileReport {
+		return &FileReport{Name: name, Kind: kind, Relro: pass, Canary: pass, PIE: pass, NX: pass}
+	}
+
+	exe := report("/usr/bin/app", KindExecutable)
+	exe.Canary = fail
+	exe.PIE = Result{Status: StatusNA, Output: "N/A"}
+	module := report("/lib/modules/x.ko", KindRelocatable)
+	module.NX = fail
+	syslib := report("/usr/lib/libx.so", KindShared)
+	syslib.Relro = Result{Status: StatusPartial, Output: "Partial RELRO"}
+	lib := report("/opt/lib/liby.so", KindShared)
+	lib.Relro = fail
+	lib.NX = fail
+
+	tests := []struct {
+		report *FileReport
+		want   []Violation
+	}{
+		// A check that does not apply is not a violation.
+		{exe, []Violation{{Path: exe.Name, Rule: "executables", Check: "canary", Result: fail}}},
+		// The first matching rule exempts modules from later rules.
+		{module, nil},
+		// Only the first matching rule applies.
+		{syslib, []Violation{{Path: syslib.Name, Rule: "system libraries", Check: "relro", Result: syslib.Relro}}},
+		{lib, []Violation{{Path: lib.Name, Rule: "libraries", Check: "nx", Result: fail}}},
+		// Reports that no rule matches are not checked.
+		{report("/boot/core", KindOther), nil},
+	}
+	for _, tt := range tests {
+		got := policy.Evaluate(tt.report)
+		if len(got) != len(tt.want) {
+			t.Errorf("Evaluate(%s) = %v, want %v", tt.report.Name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("Evaluate(%s)[%d] = %+v, want %+v", tt.report.Name, i, got[i], tt.want[i])
+			}
+		}
+	}
+
+	var none *Policy
+	if got := none.Evaluate(lib); got != nil {
+		t.Errorf("nil policy Evaluate = %v", got)
+	}
+}
+
+func TestViolation_String(t *testing.T) {
+	v := Violation{Path: "/bin/x", Rule: "executables", Check: "relro", Result: Result{Output: "No RELRO"}}
+	if got, want := v.String(), `/bin/x: relro is "No RELRO" (required by rule "executables")`; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestBinaryKind_Fixtures(t *testing.T) {
+	tests := map[string]string{
+		"all":    KindExecutable,
+		"none":   KindExecutable,
+		"dso.so": KindShared,
+		"rel.o":  KindRelocatable,
+	}
+	for name, want := range tests {
+		b, err := OpenBinary(requireFixture(t, name))
+		if err != nil {
+			t.Fatalf("OpenBinary(%s): %v", name, err)
+		}
+		if got := RunChecks(b).Kind; got != want {
+			t.Errorf("%s kind = %q, want %q", name, got, want)
+		}
+		b.Close()
+	}
+}
+
+func TestPolicy_READMEListsBuiltinChecks(t *testing.T) {
+	readme, err := os.ReadFile("../../README.md")
+	if err != nil {
+		t.Skipf("README not found: %v", err)
+	}
+	_, section, ok := strings.Cut(string(readme), "`require` takes the IDs of the checks:")
+	if !ok {
+		t.Fatal("README lacks the list of policy check IDs")
+	}
+	section, _, _ = strings.Cut(section, "\n")
+	for _, c := range Checks() {
+		if _, builtin := c.(builtinCheck); builtin && !strings.Contains(section, "`"+c.ID()+"`") {
+			t.Errorf("README policy section does not list check %q", c.ID())
+		}
+	}
+}
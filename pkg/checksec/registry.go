@@ -0,0 +1,147 @@
+package checksec
+
+import (
+	"debug/elf"
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// Check is a single binary check. Built-in checks are registered by this
+// package; other modules can add their own with Register.
+type Check interface {
+	// ID is the stable identifier of the check. It is used as the output key
+	// in machine readable formats and must match [a-z][a-z0-9_]*.
+	ID() string
+	// Header is the column title used by table output.
+	Header() string
+	// Applies reports whether the check is meaningful for binaries of the
+	// given ELF type and machine. Checks that do not apply report N/A.
+	Applies(typ elf.Type, machine elf.Machine) bool
+	// Run runs the check against the binary.
+	Run(b *Binary) Result
+}
+
+// Column is one output column produced by a check.
+type Column struct {
+	// Key is the output key used by machine readable formats.
+	Key string
+	// Header is the table column title.
+	Header string
+	// Value renders the column from the check's result. The first column of a
+	// check may leave it nil to print Result.Output colored by its status.
+	Value func(Result) string
+	// Spans optionally splits the rendered value into separately colored
+	// parts for table output. Their texts joined must equal Value's.
+	Spans func(Result) []Span
+	// Data optionally returns the structured value, such as a list, that
+	// the json, yaml and ndjson formats carry instead of the rendered text.
+	Data func(Result) any
+	// NoTable omits the column from the table row, keeping the table to one
+	// summary column per check. Verbose table output lists it below the
+	// row, and the machine readable formats still include it.
+	NoTable bool
+}
+
+// Span is a part of a column value with its own color, as understood by
+// output.ColorPrinter.
+type Span struct {
+	Text  string
+	Color string
+}
+
+// ColumnCheck is implemented by checks whose output spans several columns.
+type ColumnCheck interface {
+	Check
+	// Columns returns the check's output columns. The first column carries
+	// the check's verdict.
+	Columns() []Column
+}
+
+// Columns returns the output columns of c. Checks that do not implement
+// ColumnCheck produce a single column keyed by their ID.
+func Columns(c Check) []Column {
+	if cc, ok := c.(ColumnCheck); ok {
+		return cc.Columns()
+	}
+	return []Column{{Key: c.ID(), Header: c.Header()}}
+}
+
+// DetailCheck is implemented by checks that can explain their verdict, for
+// example by listing the relocations behind it. Verbose table output prints
+// the details below each binary's row.
+type DetailCheck interface {
+	Check
+	// Details returns one line per detail of the result, or nil.
+	Details(Result) []string
+}
+
+// Details returns the details of a result of c, or nil if c does not
+// implement DetailCheck.
+func Details(c Check, res Result) []string {
+	if dc, ok := c.(DetailCheck); ok {
+		return dc.Details(res)
+	}
+	return nil
+}
+
+var checkIDPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+var registry struct {
+	sync.RWMutex
+	checks []Check
+	ids    map[string]bool
+}
+
+// Register adds c to the set of checks run against every binary. Checks are
+// run and printed in registration order, after the built-in checks. Register
+// panics if the ID is malformed or already registered.
+func Register(c Check) {
+	registry.Lock()
+	defer registry.Unlock()
+	id := c.ID()
+	if !checkIDPattern.MatchString(id) {
+		panic(fmt.Sprintf("checksec: invalid check ID %q", id))
+	}
+	if registry.ids == nil {
+		registry.ids = make(map[string]bool)
+	}
+	if registry.ids[id] {
+		panic(fmt.Sprintf("checksec: Register called twice for check %q", id))
+	}
+	registry.ids[id] = true
+	registry.checks = append(registry.checks, c)
+}
+
+// Checks returns the registered checks in registration order.
+func Checks() []Check {
+	registry.RLock()
+	defer registry.RUnlock()
+	return append([]Check(nil), registry.checks...)
+}
+
+// RunChecks runs every registered check against b and collects the results.
+// A check that panics is reported as an error rather than aborting the scan.
+func RunChecks(b *Binary) *FileReport {
+	report := &FileReport{Name: b.Path}
+	if b.File != nil {
+		report.Kind = binaryKind(b)
+	}
+	for _, c := range Checks() {
+		if b.File != nil && !c.Applies(b.File.Type, b.File.Machine) {
+			report.SetResult(c.ID(), Result{Status: StatusNA, Output: "N/A"})
+			continue
+		}
+		report.SetResult(c.ID(), runCheck(c, b))
+	}
+	return report
+}
+
+func runCheck(c Check, b *Binary) (res Result) {
+	defer func() {
+		if r := recover(); r != nil {
+			res = Result{Status: StatusError, Output: "Error checking " + c.Header()}
+		}
+	}()
+	return c.Run(b)
+}
@@ -0,0 +1,122 @@
+package checksec
+
+import (
+	"debug/elf"
+	"testing"
+)
+
+// stubCheck is a minimal Check used to exercise the registry.
+type stubCheck struct {
+	id      string
+	applies bool
+	run     func(b *Binary) Result
+}
+
+func (c stubCheck) ID() string                         { return c.id }
+func (c stubCheck) Header() string                     { return "Stub" }
+func (c stubCheck) Applies(elf.Type, elf.Machine) bool { return c.applies }
+func (c stubCheck) Run(b *Binary) Result               { return c.run(b) }
+
+// withRegistry restores the global registry after the test.
+func withRegistry(t *testing.T) {
+	t.Helper()
+	registry.Lock()
+	checks := append([]Check(nil), registry.checks...)
+	ids := make(map[string]bool, len(registry.ids))
+	for id := range registry.ids {
+		ids[id] = true
+	}
+	registry.Unlock()
+	t.Cleanup(func() {
+		registry.Lock()
+		registry.checks, registry.ids = checks, ids
+		registry.Unlock()
+	})
+}
+
+func TestChecks_BuiltinOrder(t *testing.T) {
+	want := []string{CheckRelro, CheckCanary, CheckCfi, CheckMTE, CheckNX, CheckPIE, CheckWX, CheckTextRel, CheckRPath, CheckRunPath, CheckSymbols, CheckSafeStack, CheckFortify, CheckDangerous, CheckAnnobin, CheckCompile}
+	got := Checks()
+	if len(got) < len(want) {
+		t.Fatalf("got %d checks, want at least %d", len(got), len(want))
+	}
+	for i, id := range want {
+		if got[i].ID() != id {
+			t.Errorf("check %d = %q, want %q", i, got[i].ID(), id)
+		}
+	}
+}
+
+func TestColumns(t *testing.T) {
+	cols := Columns(stubCheck{id: "stub"})
+	if len(cols) != 1 || cols[0].Key != "stub" || cols[0].Header != "Stub" {
+		t.Errorf("Columns(stub) = %+v, want a single column keyed by ID", cols)
+	}
+
+	for _, c := range Checks() {
+		if c.ID() != CheckFortify {
+			continue
+		}
+		cols := Columns(c)
+		if len(cols) != 3 || cols[0].Key != "fortify_source" || cols[1].Key != "fortified" || cols[2].Key != "fortifyable" {
+			t.Fatalf("Columns(fortify) = %+v", cols)
+		}
+		res := Result{Value: FortifyDetails{Fortified: 4, Fortifiable: 7}}
+		if cols[1].Value(res) != "4" || cols[2].Value(res) != "7" {
+			t.Errorf("fortify columns rendered %q/%q, want 4/7", cols[1].Value(res), cols[2].Value(res))
+		}
+	}
+}
+
+func TestRegister_Panics(t *testing.T) {
+	withRegistry(t)
+	tests := []struct {
+		name string
+		id   string
+	}{
+		{"duplicate builtin", CheckRelro},
+		{"empty", ""},
+		{"uppercase", "Custom"},
+		{"spaces", "my check"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Register(%q) did not panic", tt.id)
+				}
+			}()
+			Register(stubCheck{id: tt.id})
+		})
+	}
+}
+
+func TestRunChecks_CustomCheck(t *testing.T) {
+	withRegistry(t)
+	Register(stubCheck{id: "custom", applies: true, run: func(b *Binary) Result {
+		return Result{Status: StatusPass, Output: "custom ok", Value: b.Path}
+	}})
+	Register(stubCheck{id: "skipped", applies: false, run: func(*Binary) Result {
+		t.Error("Run called on a check that does not apply")
+		return Result{}
+	}})
+	Register(stubCheck{id: "panics", applies: true, run: func(*Binary) Result {
+		panic("boom")
+	}})
+
+	file := loadFixture(t, "all")
+	report := RunChecks(&Binary{Path: fixturePath("all"), File: file})
+
+	if got, ok := report.Result("custom"); !ok || got.Output != "custom ok" || got.Value != fixturePath("all") {
+		t.Errorf("custom result = %+v, %v", got, ok)
+	}
+	if got, _ := report.Result("skipped"); got.Status != StatusNA {
+		t.Errorf("skipped status = %q, want n/a", got.Status)
+	}
+	if got, _ := report.Result("panics"); got.Status != StatusError || got.Output != "Error checking Stub" {
+		t.Errorf("panicking check = %+v, want error result", got)
+	}
+	if report.Relro.Output != "Full RELRO" {
+		t.Errorf("Relro = %q, want Full RELRO", report.Relro.Output)
+	}
+}
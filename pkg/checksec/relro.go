@@ -2,31 +2,29 @@ package checksec
 
 import (
 	"debug/elf"
-	"fmt"
 )
 
-type relro struct {
-	Output string
-	Color  string
+// RELRO reports whether the binary at name has no, partial or full RELRO.
+func RELRO(name string) (*Result, error) {
+	b, err := OpenBinary(name)
+	if err != nil {
+		return nil, err
+	}
+	defer b.Close()
+	return relroCheck(b), nil
 }
 
-func RELRO(name string) (*relro, error) {
-	res := relro{}
+// relroCheck reports whether b has no, partial or full RELRO.
+func relroCheck(b *Binary) *Result {
+	res := Result{}
 	relroHeader := false
 	bindNow := false
-
-	// To get the dynamic values
-	// Open the ELF binary file
-	file, err := elf.Open(name)
-	if err != nil {
-		return nil, fmt.Errorf("error opening ELF file: %w", err)
-	}
-	defer file.Close()
+	file := b.File
 
 	if len(file.Progs) == 0 {
-		res.Color = "italic"
+		res.Status = StatusNA
 		res.Output = "N/A"
-		return &res, nil
+		return &res
 	}
 
 	// check bind and flags and flags1.
@@ -34,19 +32,9 @@ func RELRO(name string) (*relro, error) {
 	// if (DT_FLAGS & 0x8) > 0, then DF_BIND_NOW is set
 	// if (DT_FLAGS_1 & 0x1) > 1, then DF_1_NOW is set
 	// this is depending on the compiler version used.
-	bind, _ := file.DynValue(elf.DT_BIND_NOW)
-	if len(bind) == 0 {
-		bind, _ = DynValueFromPTDynamic(file, elf.DT_BIND_NOW, name)
-	}
-	flags, _ := file.DynValue(elf.DT_FLAGS)
-	if len(flags) == 0 {
-		flags, _ = DynValueFromPTDynamic(file, elf.DT_FLAGS, name)
-	}
-
-	flags1, _ := file.DynValue(elf.DT_FLAGS_1)
-	if len(flags1) == 0 {
-		flags1, _ = DynValueFromPTDynamic(file, elf.DT_FLAGS_1, name)
-	}
+	bind := b.DynValue(elf.DT_BIND_NOW)
+	flags := b.DynValue(elf.DT_FLAGS)
+	flags1 := b.DynValue(elf.DT_FLAGS_1)
 
 	bindNow = relroBindNow(bind, flags, flags1)
 
@@ -57,17 +45,20 @@ func RELRO(name string) (*relro, error) {
 	}
 
 	if bindNow == true {
-		res.Color = "green"
+		res.Status = StatusPass
 		res.Output = "Full RELRO"
-		return &res, nil
+		res.Value = "full"
+		return &res
 	} else if relroHeader == true {
-		res.Color = "yellow"
+		res.Status = StatusPartial
 		res.Output = "Partial RELRO"
-		return &res, nil
+		res.Value = "partial"
+		return &res
 	} else {
-		res.Color = "red"
+		res.Status = StatusFail
 		res.Output = "No RELRO"
-		return &res, nil
+		res.Value = "none"
+		return &res
 	}
 }
 
@@ -43,8 +43,8 @@ func TestRELRO_StaticGoElf(t *testing.T) {
 		t.Errorf("unexpected Output = %q", result.Output)
 	}
 	validColors := map[string]bool{"green": true, "yellow": true, "red": true}
-	if !validColors[result.Color] {
-		t.Errorf("unexpected Color = %q", result.Color)
+	if !validColors[result.Color()] {
+		t.Errorf("unexpected Color = %q", result.Color())
 	}
 }
 
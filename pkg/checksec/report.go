@@ -0,0 +1,157 @@
+package checksec
+
+// Status is the verdict of a single check.
+type Status string
+
+const (
+	// StatusPass means the mitigation is fully in place.
+	StatusPass Status = "pass"
+	// StatusPartial means the mitigation is only partly in place.
+	StatusPartial Status = "partial"
+	// StatusFail means the mitigation is missing.
+	StatusFail Status = "fail"
+	// StatusUnknown means the binary does not carry enough information to decide.
+	StatusUnknown Status = "unknown"
+	// StatusNA means the check does not apply to this binary.
+	StatusNA Status = "n/a"
+	// StatusError means the check could not be run.
+	StatusError Status = "error"
+)
+
+// Color returns the presentation color for the status, as understood by
+// output.ColorPrinter.
+func (s Status) Color() string {
+	switch s {
+	case StatusPass:
+		return "green"
+	case StatusPartial, StatusUnknown:
+		return "yellow"
+	case StatusFail, StatusError:
+		return "red"
+	case StatusNA:
+		return "italic"
+	default:
+		return "unset"
+	}
+}
+
+// Result is the outcome of a single check. Output is the human readable verdict
+// printed in tables; Value is the machine readable value, whose type depends on
+// the check (see the FileReport field documentation).
+type Result struct {
+	Status Status `json:"status"`
+	Output string `json:"output"`
+	Value  any    `json:"value,omitempty"`
+}
+
+// Color returns the presentation color for the result's status.
+func (r Result) Color() string {
+	return r.Status.Color()
+}
+
+// FileReport holds the result of every file check for a single binary.
+type FileReport struct {
+	Name string `json:"name"`
+	// Kind is the kind of binary: KindExecutable, KindShared,
+	// KindRelocatable or KindOther.
+	Kind string `json:"kind,omitempty"`
+	// Relro value: "full", "partial" or "none".
+	Relro Result `json:"relro"`
+	// Canary value: CanaryMechanism, "" when no canary is found. Thread-local
+	// guard reads alone (CanaryTLS) are StatusUnknown.
+	Canary Result `json:"canary"`
+	// Cfi value: CfiFeatures.
+	Cfi Result `json:"cfi"`
+	// MTE value: MTEMode, unset when there is no memory tagging note.
+	MTE Result `json:"mte"`
+	// NX value: bool.
+	NX Result `json:"nx"`
+	// PIE value: "pie", "static-pie", "dso", "rel" or "none".
+	PIE Result `json:"pie"`
+	// WX value: WXRegions.
+	WX Result `json:"wx"`
+	// TextRel value: TextRelocations.
+	TextRel Result `json:"textrel"`
+	// RPath value: []SearchPathEntry, unset when DT_RPATH is absent.
+	RPath Result `json:"rpath"`
+	// RunPath value: []SearchPathEntry, unset when DT_RUNPATH is absent.
+	RunPath Result `json:"runpath"`
+	// Symbols value: int, the number of symbols in .symtab.
+	Symbols Result `json:"symbols"`
+	// SafeStack value: bool.
+	SafeStack Result `json:"safestack"`
+	// Fortify value: FortifyDetails.
+	Fortify Result `json:"fortify"`
+	// Dangerous value: []string, the imported functions on the deny list.
+	Dangerous Result `json:"dangerous"`
+	// Annobin value: AnnobinFlags, unset when the binary has no annobin
+	// notes.
+	Annobin Result `json:"annobin"`
+	// CompileFlags value: CompileFlags, unset when no command line was
+	// recorded.
+	CompileFlags Result `json:"compile_flags"`
+	// Extra holds the results of checks registered by other modules, keyed
+	// by check ID.
+	Extra map[string]Result `json:"extra,omitempty"`
+}
+
+// field returns the typed field holding the result of a built-in check, or
+// nil for any other ID.
+func (r *FileReport) field(id string) *Result {
+	switch id {
+	case CheckRelro:
+		return &r.Relro
+	case CheckCanary:
+		return &r.Canary
+	case CheckCfi:
+		return &r.Cfi
+	case CheckMTE:
+		return &r.MTE
+	case CheckNX:
+		return &r.NX
+	case CheckPIE:
+		return &r.PIE
+	case CheckWX:
+		return &r.WX
+	case CheckTextRel:
+		return &r.TextRel
+	case CheckRPath:
+		return &r.RPath
+	case CheckRunPath:
+		return &r.RunPath
+	case CheckSymbols:
+		return &r.Symbols
+	case CheckSafeStack:
+		return &r.SafeStack
+	case CheckFortify:
+		return &r.Fortify
+	case CheckDangerous:
+		return &r.Dangerous
+	case CheckAnnobin:
+		return &r.Annobin
+	case CheckCompile:
+		return &r.CompileFlags
+	}
+	return nil
+}
+
+// Result returns the result recorded for the check with the given ID.
+func (r *FileReport) Result(id string) (Result, bool) {
+	if f := r.field(id); f != nil {
+		return *f, f.Status != ""
+	}
+	res, ok := r.Extra[id]
+	return res, ok
+}
+
+// SetResult records the result of the check with the given ID.
+func (r *FileReport) SetResult(id string, res Result) {
+	if f := r.field(id); f != nil {
+		*f = res
+		return
+	}
+	if r.Extra == nil {
+		r.Extra = make(map[string]Result)
+	}
+	r.Extra[id] = res
+}
@@ -0,0 +1,91 @@
+package checksec
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestStatusColor(t *testing.T) {
+	tests := []struct {
+		status Status
+		want   string
+	}{
+		{StatusPass, "green"},
+		{StatusPartial, "yellow"},
+		{StatusUnknown, "yellow"},
+		{StatusFail, "red"},
+		{StatusError, "red"},
+		{StatusNA, "italic"},
+		{Status(""), "unset"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			if got := tt.status.Color(); got != tt.want {
+				t.Errorf("Status(%q).Color() = %q, want %q", tt.status, got, tt.want)
+			}
+			if got := (Result{Status: tt.status}).Color(); got != tt.want {
+				t.Errorf("Result{Status: %q}.Color() = %q, want %q", tt.status, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileReport_JSON(t *testing.T) {
+	report := FileReport{
+		Name:  "/bin/app",
+		Relro: Result{Status: StatusPass, Output: "Full RELRO", Value: "full"},
+		Fortify: Result{
+			Status: StatusPass,
+			Output: "Yes",
+			Value:  FortifyDetails{Fortified: 2},
+		},
+	}
+	raw, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	relro, ok := decoded["relro"].(map[string]any)
+	if !ok {
+		t.Fatalf("relro missing in %s", raw)
+	}
+	if relro["status"] != "pass" || relro["output"] != "Full RELRO" || relro["value"] != "full" {
+		t.Errorf("relro = %v, want pass/Full RELRO/full", relro)
+	}
+	fortify, ok := decoded["fortify"].(map[string]any)
+	if !ok {
+		t.Fatalf("fortify missing in %s", raw)
+	}
+	value, ok := fortify["value"].(map[string]any)
+	if !ok || fortify["status"] != "pass" || value["fortified"] != float64(2) {
+		t.Errorf("fortify = %v, want status and counts", fortify)
+	}
+}
+
+func TestFileReport_ResultAndSetResult(t *testing.T) {
+	var report FileReport
+	if _, ok := report.Result(CheckRelro); ok {
+		t.Error("Result(relro) on an empty report should not be ok")
+	}
+
+	relro := Result{Status: StatusPass, Output: "Full RELRO"}
+	report.SetResult(CheckRelro, relro)
+	if report.Relro != relro {
+		t.Errorf("SetResult(relro) did not populate the typed field: %+v", report.Relro)
+	}
+	if got, ok := report.Result(CheckRelro); !ok || got != relro {
+		t.Errorf("Result(relro) = %+v, %v", got, ok)
+	}
+
+	custom := Result{Status: StatusFail, Output: "custom"}
+	report.SetResult("custom", custom)
+	if got, ok := report.Result("custom"); !ok || got != custom {
+		t.Errorf("Result(custom) = %+v, %v", got, ok)
+	}
+	if report.Extra["custom"] != custom {
+		t.Errorf("custom result not stored in Extra: %+v", report.Extra)
+	}
+}
@@ -2,29 +2,18 @@ package checksec
 
 import (
 	"debug/elf"
-	"fmt"
 )
 
-type rpath struct {
-	Output string
-	Color  string
-}
-
-func RPATH(name string) (*rpath, error) {
-	res := rpath{}
-	file, err := elf.Open(name)
+func RPATH(name string) (*Result, error) {
+	b, err := OpenBinary(name)
 	if err != nil {
-		return nil, fmt.Errorf("error opening ELF file: %w", err)
+		return nil, err
 	}
-	defer file.Close()
+	defer b.Close()
+	return rpathCheck(b), nil
+}
 
-	rpath, _ := file.DynValue(15)
-	if len(rpath) == 0 {
-		res.Output = "No RPATH"
-		res.Color = "green"
-	} else {
-		res.Output = "RPATH"
-		res.Color = "red"
-	}
-	return &res, nil
+// rpathCheck reports the DT_RPATH entries of b.
+func rpathCheck(b *Binary) *Result {
+	return searchPathCheck(b, elf.DT_RPATH, "RPATH")
 }
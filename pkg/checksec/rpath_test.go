@@ -31,8 +31,8 @@ func TestRPATH_NoRpath(t *testing.T) {
 	if result.Output != "No RPATH" {
 		t.Errorf("Output = %q, want %q", result.Output, "No RPATH")
 	}
-	if result.Color != "green" {
-		t.Errorf("Color = %q, want %q", result.Color, "green")
+	if result.Color() != "green" {
+		t.Errorf("Color = %q, want %q", result.Color(), "green")
 	}
 }
 
@@ -50,7 +50,7 @@ func TestRPATH_WithRpath(t *testing.T) {
 	if result.Output != "RPATH" {
 		t.Errorf("Output = %q, want %q", result.Output, "RPATH")
 	}
-	if result.Color != "red" {
-		t.Errorf("Color = %q, want %q", result.Color, "red")
+	if result.Color() != "red" {
+		t.Errorf("Color = %q, want %q", result.Color(), "red")
 	}
 }
@@ -2,30 +2,19 @@ package checksec
 
 import (
 	"debug/elf"
-	"fmt"
 )
 
-type runpath struct {
-	Output string
-	Color  string
-}
-
 // Detect runpath in binary
-func RUNPATH(name string) (*runpath, error) {
-	res := runpath{}
-	file, err := elf.Open(name)
+func RUNPATH(name string) (*Result, error) {
+	b, err := OpenBinary(name)
 	if err != nil {
-		return nil, fmt.Errorf("error opening ELF file: %w", err)
+		return nil, err
 	}
-	defer file.Close()
+	defer b.Close()
+	return runpathCheck(b), nil
+}
 
-	runpath, _ := file.DynValue(29)
-	if len(runpath) == 0 {
-		res.Output = "No RUNPATH"
-		res.Color = "green"
-	} else {
-		res.Output = "RUNPATH"
-		res.Color = "red"
-	}
-	return &res, nil
+// runpathCheck reports the DT_RUNPATH entries of b.
+func runpathCheck(b *Binary) *Result {
+	return searchPathCheck(b, elf.DT_RUNPATH, "RUNPATH")
 }
@@ -31,8 +31,8 @@ func TestRUNPATH_NoRunpath(t *testing.T) {
 	if result.Output != "No RUNPATH" {
 		t.Errorf("Output = %q, want %q", result.Output, "No RUNPATH")
 	}
-	if result.Color != "green" {
-		t.Errorf("Color = %q, want %q", result.Color, "green")
+	if result.Color() != "green" {
+		t.Errorf("Color = %q, want %q", result.Color(), "green")
 	}
 }
 
@@ -50,7 +50,7 @@ func TestRUNPATH_WithRunpath(t *testing.T) {
 	if result.Output != "RUNPATH" {
 		t.Errorf("Output = %q, want %q", result.Output, "RUNPATH")
 	}
-	if result.Color != "red" {
-		t.Errorf("Color = %q, want %q", result.Color, "red")
+	if result.Color() != "red" {
+		t.Errorf("Color = %q, want %q", result.Color(), "red")
 	}
 }
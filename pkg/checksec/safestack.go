@@ -2,80 +2,37 @@ package checksec
 
 import (
 	"bytes"
-	"debug/elf"
-	"fmt"
-	"os"
-	"path/filepath"
 )
 
 // SafeStackInit symbol used by SafeStack-enabled binaries.
 const SafeStackInit = "__safestack_init"
 
-// SafeStackResult is the result of SafeStack detection.
-type SafeStackResult struct {
-	Output string
-	Color  string
-}
-
 func hasSafeStackSymbol(name string) bool {
 	return bytes.HasPrefix([]byte(name), []byte(SafeStackInit))
 }
 
 // SafeStack checks for SafeStack support by searching for __safestack_init.
-func SafeStack(name string) (*SafeStackResult, error) {
-	if name == "" {
-		return nil, fmt.Errorf("filename cannot be empty")
-	}
-
-	cleanPath := filepath.Clean(name)
-	if _, err := os.Stat(cleanPath); err != nil {
-		return nil, fmt.Errorf("cannot access file: %w", err)
-	}
-
-	f, err := os.Open(cleanPath)
+func SafeStack(name string) (*Result, error) {
+	b, err := OpenBinary(name)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %w", err)
-	}
-	defer f.Close()
-
-	file, err := elf.NewFile(f)
-	if err != nil {
-		return nil, fmt.Errorf("invalid ELF file: %w", err)
-	}
-
-	res := &SafeStackResult{}
-
-	if symbols, err := file.Symbols(); err == nil {
-		for _, symbol := range symbols {
-			if hasSafeStackSymbol(symbol.Name) {
-				res.Output = "SafeStack Found"
-				res.Color = "green"
-				return res, nil
-			}
-		}
-	}
-
-	if importedSymbols, err := file.ImportedSymbols(); err == nil {
-		for _, symbol := range importedSymbols {
-			if hasSafeStackSymbol(symbol.Name) {
-				res.Output = "SafeStack Found"
-				res.Color = "green"
-				return res, nil
-			}
-		}
+		return nil, err
 	}
+	defer b.Close()
+	return safeStackCheck(b), nil
+}
 
-	if dynamicFunctions, err := FunctionsFromSymbolTable(f); err == nil {
-		for _, symbol := range dynamicFunctions {
-			if hasSafeStackSymbol(symbol.Name) {
-				res.Output = "SafeStack Found"
-				res.Color = "green"
-				return res, nil
-			}
-		}
+// safeStackCheck looks for __safestack_init in the symbol tables of b.
+func safeStackCheck(b *Binary) *Result {
+	res := &Result{}
+	if b.HasSymbolPrefix(SafeStackInit) {
+		res.Status = StatusPass
+		res.Output = "SafeStack Found"
+		res.Value = true
+		return res
 	}
 
+	res.Status = StatusFail
 	res.Output = "No SafeStack Found"
-	res.Color = "red"
-	return res, nil
+	res.Value = false
+	return res
 }
@@ -0,0 +1,290 @@
+package checksec
+
+import (
+	"debug/elf"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Scanner runs the registered checks against files, directories, running
+// processes and the kernel. Every method reports failures as errors and never
+// exits, so Scanner can be used as a library. The zero value is ready to use.
+type Scanner struct {
+	// Libc is the libc used by the FORTIFY check; "" resolves it from each
+	// binary's dependencies.
+	Libc string
+	// DangerousFunctions replaces DefaultDangerousFunctions as the deny list
+	// of the dangerous check when not nil.
+	DangerousFunctions []string
+	// FortifyFilter adjusts the libc functions of the FORTIFY check when not
+	// nil.
+	FortifyFilter *FortifyFilter
+	// Jobs is the number of files scanned concurrently by ScanFiles and
+	// ScanDir; 0 uses one per CPU.
+	Jobs int
+	// Recursive makes ScanDir descend into subdirectories.
+	Recursive bool
+}
+
+// KernelReport holds the kernel config, sysctl and SELinux results of a
+// kernel scan.
+type KernelReport struct {
+	// Config is the kernel config file that was checked.
+	Config string         `json:"config"`
+	Checks []KernelResult `json:"checks"`
+}
+
+// ScanFile runs every registered check against the ELF file at path.
+func (s *Scanner) ScanFile(path string) (*FileReport, error) {
+	b, err := s.open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer b.Close()
+	return RunChecks(b), nil
+}
+
+// ScanFortify runs only the FORTIFY check against the ELF file at path.
+func (s *Scanner) ScanFortify(path string) (*Result, error) {
+	b, err := s.open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer b.Close()
+	return fortifyCheck(b)
+}
+
+// open opens the ELF file at path with the Scanner's check options.
+func (s *Scanner) open(path string) (*Binary, error) {
+	b, err := OpenBinary(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	b.Libc = s.Libc
+	b.DangerousFunctions = s.DangerousFunctions
+	b.FortifyFilter = s.FortifyFilter
+	return b, nil
+}
+
+// ScanFiles scans every path on up to Jobs concurrent workers and passes
+// each outcome to emit in the order of paths, whichever worker finishes
+// first. emit is called from the calling goroutine as soon as a result and
+// all results before it are ready; at most a few results per worker are held
+// back waiting for a slower predecessor.
+func (s *Scanner) ScanFiles(paths []string, emit func(path string, report *FileReport, err error)) {
+	type outcome struct {
+		report *FileReport
+		err    error
+	}
+	scanOrdered(len(paths), s.jobs(),
+		func(i int) outcome {
+			report, err := s.ScanFile(paths[i])
+			return outcome{report, err}
+		},
+		func(i int, o outcome) { emit(paths[i], o.report, o.err) })
+}
+
+// ScanDir scans every ELF file in dir, descending into subdirectories when
+// Recursive is set. Files that fail to scan are returned in a joined error
+// alongside the reports of the rest.
+func (s *Scanner) ScanDir(dir string) ([]*FileReport, error) {
+	files, err := ELFFiles(dir, s.Recursive)
+	if err != nil {
+		return nil, err
+	}
+	var reports []*FileReport
+	var errs []error
+	s.ScanFiles(files, func(_ string, report *FileReport, err error) {
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		reports = append(reports, report)
+	})
+	return reports, errors.Join(errs...)
+}
+
+// ScanPID scans the executable of the running process pid.
+func (s *Scanner) ScanPID(pid int) (*FileReport, error) {
+	path, err := ProcessExe(pid)
+	if err != nil {
+		return nil, err
+	}
+	return s.ScanFile(path)
+}
+
+// ScanKernel checks the kernel config file config and the sysctls of the
+// running system. An empty config locates the config of the running kernel
+// with FindKernelConfig.
+func (s *Scanner) ScanKernel(config string) (*KernelReport, error) {
+	if config == "" {
+		var err error
+		if config, err = FindKernelConfig(); err != nil {
+			return nil, err
+		}
+	}
+	checks, err := KernelConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &KernelReport{Config: config, Checks: append(checks, SysctlCheck()...)}, nil
+}
+
+// jobs returns the number of concurrent workers to use.
+func (s *Scanner) jobs() int {
+	if s.Jobs > 0 {
+		return s.Jobs
+	}
+	return runtime.NumCPU()
+}
+
+// scanOrdered calls scan for every index below n on up to jobs workers and
+// passes the results to emit in index order from the calling goroutine. A
+// window bounds the number of results scanned but not yet emitted.
+func scanOrdered[T any](n, jobs int, scan func(int) T, emit func(int, T)) {
+	jobs = max(1, min(jobs, n))
+
+	type result struct {
+		index int
+		value T
+	}
+	indexes := make(chan int)
+	results := make(chan result)
+	window := make(chan struct{}, 4*jobs)
+
+	var wg sync.WaitGroup
+	for range jobs {
+		wg.Go(func() {
+			for i := range indexes {
+				results <- result{i, scan(i)}
+			}
+		})
+	}
+	go func() {
+		for i := range n {
+			window <- struct{}{}
+			indexes <- i
+		}
+		close(indexes)
+		wg.Wait()
+		close(results)
+	}()
+
+	pending := make(map[int]T)
+	next := 0
+	for r := range results {
+		pending[r.index] = r.value
+		for value, ok := pending[next]; ok; value, ok = pending[next] {
+			delete(pending, next)
+			emit(next, value)
+			next++
+			<-window
+		}
+	}
+}
+
+// isELFFn is an indirection for testability.
+var isELFFn = IsELF
+
+// IsELF reports whether path can be parsed as an ELF file.
+func IsELF(path string) bool {
+	file, err := elf.Open(path)
+	if err != nil {
+		return false
+	}
+	file.Close()
+	return true
+}
+
+// ELFFiles returns the sorted paths of the ELF files in dir, descending into
+// subdirectories when recursive is set. It is an error for dir to be missing,
+// not a directory or to contain no ELF files.
+func ELFFiles(dir string, recursive bool) ([]string, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("directory not found: %v", dir)
+		}
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	var results []string
+	if recursive {
+		filepath.WalkDir(dir, func(path string, file fs.DirEntry, err error) error {
+			if err != nil {
+				return fs.SkipDir
+			}
+			if !file.IsDir() && file.Type().IsRegular() && isELFFn(path) {
+				results = append(results, path)
+			}
+			return nil
+		})
+	} else {
+		fileList, _ := filepath.Glob(filepath.Join(dir, "*"))
+		for _, path := range fileList {
+			info, err := os.Stat(path)
+			if err != nil || info.IsDir() {
+				continue
+			}
+			if isELFFn(path) {
+				results = append(results, path)
+			}
+		}
+	}
+
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no binary files found in %s", dir)
+	}
+	sort.Strings(results)
+	return results, nil
+}
+
+// ProcessExe returns the path of the executable of the running process pid.
+// When the executable was deleted or lives in another mount namespace the
+// /proc/<pid>/exe link itself is returned, since it still opens the file.
+func ProcessExe(pid int) (string, error) {
+	link := filepath.Join("/proc", strconv.Itoa(pid), "exe")
+	target, err := os.Readlink(link)
+	if err != nil {
+		switch {
+		case os.IsNotExist(err):
+			return "", fmt.Errorf("pid %d not found", pid)
+		case os.IsPermission(err):
+			return "", fmt.Errorf("permission denied to access %s", link)
+		}
+		return "", err
+	}
+	target = strings.TrimSuffix(target, " (deleted)")
+	if _, err := os.Stat(target); err != nil {
+		return link, nil
+	}
+	return target, nil
+}
+
+// FindKernelConfig returns the config of the running kernel, either
+// /proc/config.gz or /boot/config-<release>.
+func FindKernelConfig() (string, error) {
+	if _, err := os.Stat("/proc/config.gz"); err == nil {
+		return "/proc/config.gz", nil
+	}
+	release, err := os.ReadFile("/proc/sys/kernel/osrelease")
+	if err != nil {
+		return "", fmt.Errorf("could not find kernel config: %w", err)
+	}
+	config := "/boot/config-" + strings.TrimSpace(string(release))
+	if _, err := os.Stat(config); err != nil {
+		return "", fmt.Errorf("could not find kernel config: %w", err)
+	}
+	return config, nil
+}
@@ -0,0 +1,238 @@
+package checksec
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScanner_ScanFile(t *testing.T) {
+	path := requireFixture(t, "all")
+	var s Scanner
+
+	report, err := s.ScanFile(path)
+	if err != nil {
+		t.Fatalf("ScanFile: %v", err)
+	}
+	if report.Name != path || report.Relro.Output != "Full RELRO" {
+		t.Errorf("report = %q / %q, want %q / Full RELRO", report.Name, report.Relro.Output, path)
+	}
+
+	if _, err := s.ScanFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("ScanFile(missing) returned no error")
+	}
+}
+
+func TestScanner_ScanFileWithoutELF(t *testing.T) {
+	// A Binary without a parsed file yields error results instead of failing.
+	report := RunChecks(&Binary{Path: filepath.Join(t.TempDir(), "missing")})
+	if report.PIE.Status != StatusError {
+		t.Errorf("PIE status = %q, want error", report.PIE.Status)
+	}
+	if report.Relro.Status != StatusError {
+		t.Errorf("RELRO status = %q, want error", report.Relro.Status)
+	}
+}
+
+func TestScanner_ScanDir(t *testing.T) {
+	data, err := os.ReadFile(requireFixture(t, "none"))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "nested")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	for _, p := range []string{filepath.Join(dir, "b"), filepath.Join(dir, "a"), filepath.Join(sub, "c")} {
+		if err := os.WriteFile(p, data, 0o755); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "text"), []byte("not an elf"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	tests := []struct {
+		recursive bool
+		want      []string
+	}{
+		{false, []string{"a", "b"}},
+		{true, []string{"a", "b", "nested/c"}},
+	}
+	for _, tt := range tests {
+		s := Scanner{Jobs: 2, Recursive: tt.recursive}
+		reports, err := s.ScanDir(dir)
+		if err != nil {
+			t.Fatalf("ScanDir(recursive=%v): %v", tt.recursive, err)
+		}
+		if len(reports) != len(tt.want) {
+			t.Fatalf("ScanDir(recursive=%v) = %d reports, want %d", tt.recursive, len(reports), len(tt.want))
+		}
+		for i, report := range reports {
+			if want := filepath.Join(dir, tt.want[i]); report.Name != want {
+				t.Errorf("reports[%d] = %q, want %q", i, report.Name, want)
+			}
+		}
+	}
+}
+
+func TestScanner_ScanDirErrors(t *testing.T) {
+	var s Scanner
+	dir := t.TempDir()
+	file := filepath.Join(dir, "afile")
+	if err := os.WriteFile(file, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		dir  string
+		want string
+	}{
+		{"missing", filepath.Join(dir, "no-such-dir"), "directory not found"},
+		{"not a directory", file, "is not a directory"},
+		{"no binaries", dir, "no binary files found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.ScanDir(tt.dir); err == nil || !contains(err.Error(), tt.want) {
+				t.Errorf("ScanDir(%q) error = %v, want %q", tt.dir, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestELFFiles_UsesIsELF(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"elf1", "text1"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("dummy"), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	orig := isELFFn
+	defer func() { isELFFn = orig }()
+	isELFFn = func(path string) bool { return filepath.Base(path) == "elf1" }
+
+	got, err := ELFFiles(dir, false)
+	if err != nil || len(got) != 1 || filepath.Base(got[0]) != "elf1" {
+		t.Fatalf("ELFFiles = %#v, %v; want only elf1", got, err)
+	}
+}
+
+func TestScanner_ScanPID(t *testing.T) {
+	if _, err := os.Stat("/proc/self/exe"); err != nil {
+		t.Skipf("no procfs: %v", err)
+	}
+	var s Scanner
+	report, err := s.ScanPID(os.Getpid())
+	if err != nil {
+		t.Fatalf("ScanPID(self): %v", err)
+	}
+	if report.Name == "" || report.NX.Status == "" {
+		t.Errorf("report = %+v, want a scanned test binary", report)
+	}
+
+	if _, err := s.ScanPID(-1); err == nil || !contains(err.Error(), "pid -1 not found") {
+		t.Errorf("ScanPID(-1) error = %v", err)
+	}
+}
+
+func TestScanner_ScanKernel(t *testing.T) {
+	fixture := "../../tests/kernel.config"
+	if _, err := os.Stat(fixture); err != nil {
+		t.Skipf("kernel.config fixture not found: %v", err)
+	}
+	var s Scanner
+	report, err := s.ScanKernel(fixture)
+	if err != nil {
+		t.Fatalf("ScanKernel: %v", err)
+	}
+	if report.Config != fixture {
+		t.Errorf("Config = %q, want %q", report.Config, fixture)
+	}
+	types := map[string]bool{}
+	for _, check := range report.Checks {
+		types[check.Type] = true
+	}
+	for _, typ := range []string{"Kernel Config", "SELinux", "Sysctl"} {
+		if !types[typ] {
+			t.Errorf("no %s results in %+v", typ, report.Checks)
+		}
+	}
+
+	if _, err := s.ScanKernel(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("ScanKernel(missing) returned no error")
+	}
+}
+
+func TestScanOrdered_PreservesOrder(t *testing.T) {
+	var running, peak atomic.Int32
+	scan := func(i int) int {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		// Later items finish first.
+		time.Sleep(time.Duration(20-i) * time.Millisecond)
+		running.Add(-1)
+		return i
+	}
+
+	var got []int
+	scanOrdered(20, 4, scan, func(i, v int) {
+		if i != v {
+			t.Errorf("emit(%d, %d): index and value differ", i, v)
+		}
+		got = append(got, v)
+	})
+	if len(got) != 20 {
+		t.Fatalf("got %d results, want 20", len(got))
+	}
+	for i, v := range got {
+		if v != i {
+			t.Errorf("results[%d] = %d", i, v)
+		}
+	}
+	if p := peak.Load(); p < 2 || p > 4 {
+		t.Errorf("peak concurrency = %d, want between 2 and 4", p)
+	}
+}
+
+func TestScanOrdered_BoundsPendingResults(t *testing.T) {
+	// The first item is slow, so every later result has to wait for it.
+	var started atomic.Int32
+	scan := func(i int) string {
+		started.Add(1)
+		if i == 0 {
+			time.Sleep(50 * time.Millisecond)
+		}
+		return strconv.Itoa(i)
+	}
+
+	const jobs = 2
+	emitted := 0
+	scanOrdered(100, jobs, scan, func(i int, _ string) {
+		if i == 0 {
+			if held := int(started.Load()); held > 4*jobs+jobs {
+				t.Errorf("%d items scanned while waiting for the first, want at most %d", held, 5*jobs)
+			}
+		}
+		emitted++
+	})
+	if emitted != 100 {
+		t.Errorf("emitted %d results, want 100", emitted)
+	}
+}
+
+func TestScanOrdered_Empty(t *testing.T) {
+	scanOrdered(0, 8, func(int) int { return 0 }, func(i, _ int) {
+		t.Errorf("unexpected result %d for no items", i)
+	})
+}
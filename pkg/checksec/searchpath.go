@@ -0,0 +1,207 @@
+package checksec
+
+import (
+	"debug/elf"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// PathRisk classifies a DT_RPATH or DT_RUNPATH entry.
+type PathRisk string
+
+const (
+	// PathRiskNone is an absolute path into a directory only root or the
+	// binary's owner can write.
+	PathRiskNone PathRisk = ""
+	// PathRiskUnusual is an entry worth a look that cannot be abused by
+	// itself, such as a missing or group-writable directory.
+	PathRiskUnusual PathRisk = "unusual"
+	// PathRiskExploitable is an entry that lets other users plant libraries,
+	// such as a relative or world-writable directory.
+	PathRiskExploitable PathRisk = "exploitable"
+)
+
+// Color returns the presentation color for the risk, as understood by
+// output.ColorPrinter.
+func (r PathRisk) Color() string {
+	switch r {
+	case PathRiskUnusual:
+		return "yellow"
+	case PathRiskExploitable:
+		return "red"
+	}
+	return "unset"
+}
+
+// SearchPathEntry is one directory of a DT_RPATH or DT_RUNPATH.
+type SearchPathEntry struct {
+	// Path is the entry as recorded, before $ORIGIN is expanded.
+	Path string `json:"path"`
+	// Risk is the worst classification of the entry.
+	Risk PathRisk `json:"risk,omitempty"`
+	// Reasons explains Risk, one reason per finding.
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// tempDirs are world-writable by design.
+var tempDirs = []string{"/tmp", "/var/tmp", "/dev/shm"}
+
+// searchPath reads the colon separated list of the dynamic entry tag from
+// DT_STRTAB and classifies every entry. It works from PT_DYNAMIC and the
+// PT_LOAD segments, so section-stripped files are covered too.
+func searchPath(b *Binary, tag elf.DynTag) []SearchPathEntry {
+	var entries []SearchPathEntry
+	for _, off := range b.DynValue(tag) {
+		list, ok := dynString(b, off)
+		if !ok {
+			entries = append(entries, SearchPathEntry{
+				Risk:    PathRiskUnusual,
+				Reasons: []string{"string not found in DT_STRTAB"},
+			})
+			continue
+		}
+		for _, path := range strings.Split(list, ":") {
+			entries = append(entries, classifyPath(b.Path, path))
+		}
+	}
+	return entries
+}
+
+// dynString returns the NUL-terminated string at offset off of DT_STRTAB.
+func dynString(b *Binary, off uint64) (string, bool) {
+	strtab, strsz := b.DynValue(elf.DT_STRTAB), b.DynValue(elf.DT_STRSZ)
+	if len(strtab) == 0 || len(strsz) == 0 || off >= strsz[0] {
+		return "", false
+	}
+	data, err := readVirtual(b.File, strtab[0], strsz[0])
+	if err != nil {
+		return "", false
+	}
+	s, _, _ := strings.Cut(string(data[off:]), "\x00")
+	return s, true
+}
+
+// classifyPath classifies a single search path entry of the binary at
+// binPath. Directories are inspected on the scanned filesystem, with $ORIGIN
+// expanded to the binary's directory.
+func classifyPath(binPath, path string) SearchPathEntry {
+	entry := SearchPathEntry{Path: path}
+	add := func(risk PathRisk, reason string) {
+		if risk == PathRiskExploitable || entry.Risk == PathRiskNone {
+			entry.Risk = risk
+		}
+		entry.Reasons = append(entry.Reasons, reason)
+	}
+
+	binInfo, binErr := os.Stat(binPath)
+	origin := strings.Contains(path, "$ORIGIN") || strings.Contains(path, "${ORIGIN}")
+	switch {
+	case path == "":
+		add(PathRiskExploitable, "empty entry searches the current directory")
+		return entry
+	case origin && binErr == nil && binInfo.Mode()&(os.ModeSetuid|os.ModeSetgid) != 0:
+		add(PathRiskExploitable, "$ORIGIN in a setuid or setgid binary")
+	case !origin && !strings.HasPrefix(path, "/"):
+		add(PathRiskExploitable, "relative to the current directory")
+		return entry
+	}
+
+	dir := path
+	if origin {
+		abs, err := filepath.Abs(binPath)
+		if err != nil {
+			return entry
+		}
+		dir = strings.NewReplacer("${ORIGIN}", filepath.Dir(abs), "$ORIGIN", filepath.Dir(abs)).Replace(path)
+	}
+	if strings.Contains(dir, "$") {
+		// $LIB and $PLATFORM depend on the loader.
+		return entry
+	}
+	dir = filepath.Clean(dir)
+	for _, tmp := range tempDirs {
+		if dir == tmp || strings.HasPrefix(dir, tmp+"/") {
+			add(PathRiskExploitable, "in "+tmp)
+		}
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		add(PathRiskUnusual, "directory does not exist")
+		return entry
+	}
+	mode := info.Mode().Perm()
+	switch {
+	case mode&0o002 != 0:
+		add(PathRiskExploitable, "world-writable directory")
+	case mode&0o020 != 0:
+		add(PathRiskUnusual, "group-writable directory")
+	}
+	if st, ok := info.Sys().(*syscall.Stat_t); ok && st.Uid != 0 {
+		if binSt, ok := sysStat(binInfo, binErr); !ok || binSt.Uid != st.Uid {
+			add(PathRiskUnusual, fmt.Sprintf("owned by uid %d", st.Uid))
+		}
+	}
+	return entry
+}
+
+func sysStat(info os.FileInfo, err error) (*syscall.Stat_t, bool) {
+	if err != nil {
+		return nil, false
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	return st, ok
+}
+
+// searchPathCheck reports the entries of tag as a result named after it, such
+// as "RPATH". A present search path fails; its entries are colored by risk.
+func searchPathCheck(b *Binary, tag elf.DynTag, name string) *Result {
+	if len(b.DynValue(tag)) == 0 {
+		return &Result{Status: StatusPass, Output: "No " + name}
+	}
+	return &Result{Status: StatusFail, Output: name, Value: searchPath(b, tag)}
+}
+
+// SearchPathOf returns the entries carried by an RPATH or RUNPATH result.
+func SearchPathOf(r Result) []SearchPathEntry {
+	entries, _ := r.Value.([]SearchPathEntry)
+	return entries
+}
+
+// searchPathText renders the entries of an RPATH or RUNPATH result as the
+// colon separated list recorded in the binary.
+func searchPathText(r Result) string {
+	var paths []string
+	for _, e := range SearchPathOf(r) {
+		paths = append(paths, e.Path)
+	}
+	return strings.Join(paths, ":")
+}
+
+// searchPathSpans renders the entries of an RPATH or RUNPATH result colored
+// by risk and separated by colons.
+func searchPathSpans(r Result) []Span {
+	var spans []Span
+	for i, e := range SearchPathOf(r) {
+		if i > 0 {
+			spans = append(spans, Span{Text: ":", Color: "unset"})
+		}
+		spans = append(spans, Span{Text: e.Path, Color: e.Risk.Color()})
+	}
+	return spans
+}
+
+// searchPathDetails explains every entry of an RPATH or RUNPATH result that
+// is not PathRiskNone.
+func searchPathDetails(r Result) []string {
+	var lines []string
+	for _, e := range SearchPathOf(r) {
+		if e.Risk != PathRiskNone {
+			lines = append(lines, fmt.Sprintf("%q is %s: %s", e.Path, e.Risk, strings.Join(e.Reasons, ", ")))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,129 @@
+package checksec
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestClassifyPath(t *testing.T) {
+	// t.TempDir is usually under /tmp, which would flag every entry.
+	saved := tempDirs
+	tempDirs = []string{"/checksec-tmp"}
+	t.Cleanup(func() { tempDirs = saved })
+
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "app")
+	if err := os.WriteFile(bin, nil, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	setuid := filepath.Join(dir, "suid")
+	if err := os.WriteFile(setuid, nil, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(setuid, 0o755|os.ModeSetuid); err != nil {
+		t.Fatal(err)
+	}
+	mkdir := func(name string, perm os.FileMode) string {
+		p := filepath.Join(dir, name)
+		if err := os.Mkdir(p, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(p, perm); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	private := mkdir("private", 0o755)
+	world := mkdir("world", 0o777|os.ModeSticky)
+	group := mkdir("group", 0o775)
+
+	tests := []struct {
+		name    string
+		bin     string
+		path    string
+		risk    PathRisk
+		reasons []string
+	}{
+		{"owned directory", bin, private, PathRiskNone, nil},
+		{"origin", bin, "$ORIGIN/private", PathRiskNone, nil},
+		{"braced origin", bin, "${ORIGIN}", PathRiskNone, nil},
+		{"empty", bin, "", PathRiskExploitable, []string{"empty entry searches the current directory"}},
+		{"relative", bin, "lib", PathRiskExploitable, []string{"relative to the current directory"}},
+		{"dot", bin, "./", PathRiskExploitable, []string{"relative to the current directory"}},
+		{"origin in setuid", setuid, "$ORIGIN/private", PathRiskExploitable, []string{"$ORIGIN in a setuid or setgid binary"}},
+		{"world-writable", bin, world, PathRiskExploitable, []string{"world-writable directory"}},
+		{"group-writable", bin, group, PathRiskUnusual, []string{"group-writable directory"}},
+		{"missing", bin, filepath.Join(dir, "missing"), PathRiskUnusual, []string{"directory does not exist"}},
+		{"loader token", bin, "/usr/$LIB", PathRiskNone, nil},
+		{"tmp", bin, "/checksec-tmp/lib", PathRiskExploitable, []string{"in /checksec-tmp", "directory does not exist"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classifyPath(tt.bin, tt.path)
+			if got.Path != tt.path || got.Risk != tt.risk || !reflect.DeepEqual(got.Reasons, tt.reasons) {
+				t.Errorf("classifyPath(%q) = %+v, want risk %q reasons %q", tt.path, got, tt.risk, tt.reasons)
+			}
+		})
+	}
+}
+
+func TestSearchPathCheck_Fixtures(t *testing.T) {
+	tests := []struct {
+		fixture string
+		check   func(*Binary) *Result
+		output  string
+		paths   string
+	}{
+		{"rpath", rpathCheck, "RPATH", "./"},
+		{"runpath", runpathCheck, "RUNPATH", "./"},
+		{"all", rpathCheck, "No RPATH", ""},
+		{"all", runpathCheck, "No RUNPATH", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture+"/"+tt.output, func(t *testing.T) {
+			b, err := OpenBinary(requireFixture(t, tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer b.Close()
+
+			res := tt.check(b)
+			if res.Output != tt.output || searchPathText(*res) != tt.paths {
+				t.Errorf("check = %q %q, want %q %q", res.Output, searchPathText(*res), tt.output, tt.paths)
+			}
+			if tt.paths != "" && SearchPathOf(*res)[0].Risk != PathRiskExploitable {
+				t.Errorf("entries = %+v, want the relative entry flagged", SearchPathOf(*res))
+			}
+		})
+	}
+}
+
+func TestSearchPathSpansAndDetails(t *testing.T) {
+	res := Result{Value: []SearchPathEntry{
+		{Path: "/opt/app/lib"},
+		{Path: "/srv/shared", Risk: PathRiskUnusual, Reasons: []string{"group-writable directory"}},
+		{Path: "", Risk: PathRiskExploitable, Reasons: []string{"empty entry searches the current directory"}},
+	}}
+	wantSpans := []Span{
+		{Text: "/opt/app/lib", Color: "unset"},
+		{Text: ":", Color: "unset"},
+		{Text: "/srv/shared", Color: "yellow"},
+		{Text: ":", Color: "unset"},
+		{Text: "", Color: "red"},
+	}
+	if got := searchPathSpans(res); !reflect.DeepEqual(got, wantSpans) {
+		t.Errorf("searchPathSpans = %+v, want %+v", got, wantSpans)
+	}
+	if got := searchPathText(res); got != "/opt/app/lib:/srv/shared:" {
+		t.Errorf("searchPathText = %q", got)
+	}
+	wantDetails := []string{
+		`"/srv/shared" is unusual: group-writable directory`,
+		`"" is exploitable: empty entry searches the current directory`,
+	}
+	if got := searchPathDetails(res); !reflect.DeepEqual(got, wantDetails) {
+		t.Errorf("searchPathDetails = %q, want %q", got, wantDetails)
+	}
+}
@@ -5,34 +5,34 @@ import (
 	"debug/elf"
 	"encoding/binary"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/slimm609/checksec/v3/pkg/output"
 )
 
-type symbols struct {
-	Output string
-	Color  string
-}
-
 // SYMBOLS detects usage of elf symbols
-func SYMBOLS(name string) (*symbols, error) {
-	res := symbols{}
-	file, err := elf.Open(name)
+func SYMBOLS(name string) (*Result, error) {
+	b, err := OpenBinary(name)
 	if err != nil {
-		return nil, fmt.Errorf("error opening ELF file: %w", err)
+		return nil, err
 	}
-	defer file.Close()
+	defer b.Close()
+	return symbolsCheck(b), nil
+}
 
-	symbols, _ := file.Symbols()
+func symbolsCheck(b *Binary) *Result {
+	res := Result{}
+	symbols, _ := b.Symbols()
 	if len(symbols) == 0 {
+		res.Status = StatusPass
 		res.Output = "No Symbols"
-		res.Color = "green"
 	} else {
+		res.Status = StatusFail
 		res.Output = fmt.Sprintf("%d symbols", len(symbols))
-		res.Color = "red"
 	}
-	return &res, nil
+	res.Value = len(symbols)
+	return &res
 }
 
 func DynValueFromPTDynamic(file *elf.File, tag elf.DynTag, names ...string) ([]uint64, error) {
@@ -79,33 +79,45 @@ func scanDynamicEntries(data []byte, class elf.Class, bo binary.ByteOrder, tag e
 	return 0, false
 }
 
+// FunctionsFromSymbolTable returns the function symbols found through the
+// DT_SYMTAB entry of the PT_DYNAMIC segment. It handles stripped binaries with
+// no section headers.
 func FunctionsFromSymbolTable(file *os.File) ([]elf.Symbol, error) {
-	// Iterate over the dynamic section to handle stripped binaries with no sections
-	var functions []elf.Symbol
-
 	fileInfo, statErr := file.Stat()
 	if statErr != nil {
-		return functions, statErr
-	}
-	if fileInfo.Size() <= 0 {
-		return functions, fmt.Errorf("invalid file size: %d", fileInfo.Size())
+		return nil, statErr
 	}
-	fileSize := uint64(fileInfo.Size())
 
 	f, err := elf.NewFile(file)
 	if err != nil {
 		output.Warnf("Error parsing ELF file %s: %v", file.Name(), err)
-		return functions, err
+		return nil, err
 	}
 
-	symTabOffset, _ := DynValueFromPTDynamic(f, elf.DT_SYMTAB, file.Name())
-	strTabOffset, _ := DynValueFromPTDynamic(f, elf.DT_STRTAB, file.Name())
-	strTabSize, _ := DynValueFromPTDynamic(f, elf.DT_STRSZ, file.Name())
-	symEntSizeVals, _ := DynValueFromPTDynamic(f, elf.DT_SYMENT, file.Name())
+	dynValue := func(tag elf.DynTag) []uint64 {
+		v, _ := DynValueFromPTDynamic(f, tag, file.Name())
+		return v
+	}
+	return functionsFromDynamic(f, file, fileInfo.Size(), dynValue, file.Name())
+}
 
-	if symTabOffset == nil || strTabSize == nil || strTabOffset == nil {
-		return functions, err
+// functionsFromDynamic reads the function symbols of the table located by
+// DT_SYMTAB/DT_STRTAB from file, a reader over the whole ELF file of the given
+// size. name is only used in warnings.
+func functionsFromDynamic(f *elf.File, file io.ReaderAt, size int64, dynValue func(elf.DynTag) []uint64, name string) ([]elf.Symbol, error) {
+	var functions []elf.Symbol
+	var err error
+
+	if size <= 0 {
+		return functions, fmt.Errorf("invalid file size: %d", size)
 	}
+	fileSize := uint64(size)
+
+	symTabOffset := dynValue(elf.DT_SYMTAB)
+	strTabOffset := dynValue(elf.DT_STRTAB)
+	strTabSize := append([]uint64(nil), dynValue(elf.DT_STRSZ)...)
+	symEntSizeVals := dynValue(elf.DT_SYMENT)
+
 	if len(symTabOffset) == 0 || len(strTabOffset) == 0 || len(strTabSize) == 0 {
 		return functions, err
 	}
@@ -158,7 +170,7 @@ func FunctionsFromSymbolTable(file *os.File) ([]elf.Symbol, error) {
 	symData := make([]byte, symTableSize)
 	_, err = file.ReadAt(symData, int64(symTabOffset[0]))
 	if err != nil {
-		output.Warnf("Error reading symbol table for %s: %v", file.Name(), err)
+		output.Warnf("Error reading symbol table for %s: %v", name, err)
 		return functions, err
 	}
 
@@ -166,7 +178,7 @@ func FunctionsFromSymbolTable(file *os.File) ([]elf.Symbol, error) {
 	strData := make([]byte, strTabSize[0])
 	_, err = file.ReadAt(strData, int64(strTabOffset[0]))
 	if err != nil {
-		output.Warnf("Error reading string table for %s: %v", file.Name(), err)
+		output.Warnf("Error reading string table for %s: %v", name, err)
 		return functions, err
 	}
 
@@ -192,7 +204,7 @@ func FunctionsFromSymbolTable(file *os.File) ([]elf.Symbol, error) {
 			sym := elf.Sym64{}
 			err := binary.Read(bytes.NewReader(symData[i:i+symSize]), bo, &sym)
 			if err != nil {
-				output.Warnf("Error reading symbol in %s: %v", file.Name(), err)
+				output.Warnf("Error reading symbol in %s: %v", name, err)
 				continue
 			}
 
@@ -216,7 +228,7 @@ func FunctionsFromSymbolTable(file *os.File) ([]elf.Symbol, error) {
 			sym := elf.Sym32{}
 			err := binary.Read(bytes.NewReader(symData[i:i+symSize]), bo, &sym)
 			if err != nil {
-				output.Warnf("Error reading symbol in %s: %v", file.Name(), err)
+				output.Warnf("Error reading symbol in %s: %v", name, err)
 				continue
 			}
 
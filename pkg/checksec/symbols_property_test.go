@@ -64,3 +64,16 @@ func TestProp_ScanDynamicEntries_TruncatedTailIgnored(t *testing.T) {
 		}
 	})
 }
+
+// collectDynamicEntries, which backs Binary.DynValue, must be as bounds-safe
+// as scanDynamicEntries.
+func TestProp_CollectDynamicEntries_NeverPanic(t *testing.T) {
+	classes := []elf.Class{elf.ELFCLASS32, elf.ELFCLASS64}
+	orders := []binary.ByteOrder{binary.LittleEndian, binary.BigEndian}
+	rapid.Check(t, func(t *rapid.T) {
+		data := rapid.SliceOfN(rapid.Byte(), 0, 256).Draw(t, "data")
+		class := rapid.SampledFrom(classes).Draw(t, "class")
+		bo := rapid.SampledFrom(orders).Draw(t, "bo")
+		collectDynamicEntries(data, class, bo, make(map[elf.DynTag][]uint64))
+	})
+}
@@ -31,8 +31,8 @@ func TestSYMBOLS_WithSymbols(t *testing.T) {
 	if result.Output == "No Symbols" {
 		t.Skip("binary was unexpectedly stripped — skipping symbols-present check")
 	}
-	if result.Color != "red" {
-		t.Errorf("Color = %q, want %q for binary with symbols", result.Color, "red")
+	if result.Color() != "red" {
+		t.Errorf("Color = %q, want %q for binary with symbols", result.Color(), "red")
 	}
 }
 
@@ -60,7 +60,7 @@ func TestSYMBOLS_Stripped(t *testing.T) {
 	if result.Output != "No Symbols" {
 		t.Errorf("Output = %q, want %q for stripped binary", result.Output, "No Symbols")
 	}
-	if result.Color != "green" {
-		t.Errorf("Color = %q, want %q for stripped binary", result.Color, "green")
+	if result.Color() != "green" {
+		t.Errorf("Color = %q, want %q for stripped binary", result.Color(), "green")
 	}
 }
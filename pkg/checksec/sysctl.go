@@ -1,76 +1,73 @@
 package checksec
 
 import (
+	"fmt"
 	"runtime"
 
 	"github.com/lorenzosaino/go-sysctl"
 )
 
-func SysctlCheck() ([]interface{}, []interface{}) {
-	var Results []interface{}
-	var ColorResults []interface{}
+// sysctlValue is the verdict for one value of a sysctl.
+type sysctlValue struct {
+	res    string
+	status Status
+}
 
-	sysctlChecks := []map[string]interface{}{
-		{"name": "fs.protected_symlinks", "desc": "Protected symlinks", "values": map[string]map[string]string{"0": {"res": "Disabled", "color": "red"}, "1": {"res": "Enabled", "color": "green"}}},
-		{"name": "fs.protected_hardlinks", "desc": "Protected hardlinks", "values": map[string]map[string]string{"0": {"res": "Disabled", "color": "red"}, "1": {"res": "Enabled", "color": "green"}}},
-		{"name": "net.ipv4.conf.all.rp_filter", "desc": "Ipv4 reverse path filtering", "values": map[string]map[string]string{"0": {"res": "Disabled", "color": "red"}, "1": {"res": "Enabled", "color": "green"}}},
-		{"name": "kernel.yama.ptrace_scope", "desc": "YAMA", "values": map[string]map[string]string{"0": {"res": "Disabled", "color": "red"}, "1": {"res": "Enabled", "color": "green"}}},
-		{"name": "kernel.exec-shield", "desc": "Exec Shield", "values": map[string]map[string]string{"0": {"res": "Disabled", "color": "red"}, "1": {"res": "Enabled", "color": "green"}}},
-		{"name": "kernel.unprivileged_bpf_disabled", "desc": "Unprivileged BPF Disabled", "values": map[string]map[string]string{"0": {"res": "Disabled", "color": "red"}, "1": {"res": "Enabled", "color": "green"}}},
-		{"name": "kernel.randomize_va_space", "desc": "Vanilla Kernel ASLR", "values": map[string]map[string]string{"0": {"res": "Disabled", "color": "red"}, "1": {"res": "Partial", "color": "yellow"}, "2": {"res": "Enabled", "color": "green"}}},
-		{"name": "kernel.dmesg_restrict", "desc": "Dmesg Restrictions", "values": map[string]map[string]string{"0": {"res": "Disabled", "color": "red"}, "1": {"res": "Enabled", "color": "green"}}},
-		{"name": "kernel.kptr_restrict", "desc": "Kernel Pointer Restrictions", "values": map[string]map[string]string{"0": {"res": "Disabled", "color": "red"}, "1": {"res": "Partial", "color": "yellow"}, "2": {"res": "Enabled", "color": "green"}}},
-		{"name": "fs.protected_fifos", "desc": "Protected fifos", "values": map[string]map[string]string{"0": {"res": "Disabled", "color": "red"}, "1": {"res": "Partial", "color": "yellow"}, "2": {"res": "Enabled", "color": "green"}}},
-		{"name": "fs.protected_regular", "desc": "Protected regular", "values": map[string]map[string]string{"0": {"res": "Disabled", "color": "red"}, "1": {"res": "Partial", "color": "yellow"}, "2": {"res": "Enabled", "color": "green"}}},
-		{"name": "kernel.perf_event_paranoid", "desc": "Performance events by normal users", "values": map[string]map[string]string{"-1": {"res": "Disabled", "color": "red"}, "0": {"res": "Disabled", "color": "red"}, "1": {"res": "Partial", "color": "yellow"}, "2": {"res": "Enabled", "color": "green"}, "3": {"res": "Enabled", "color": "green"}}},
-		{"name": "dev.tty.ldisc_autoload", "desc": "Disable Autoload TTY Line Disciplines", "values": map[string]map[string]string{"1": {"res": "Disabled", "color": "red"}, "0": {"res": "Enabled", "color": "green"}}},
-		{"name": "dev.tty.legacy_tiocsti", "desc": "Disable Legacy TIOCSTI (breaks screen readers)", "values": map[string]map[string]string{"1": {"res": "Disabled", "color": "red"}, "0": {"res": "Enabled", "color": "green"}}},
-		{"name": "kernel.kexec_load_disabled", "desc": "Turn off kexec", "values": map[string]map[string]string{"0": {"res": "Disabled", "color": "red"}, "1": {"res": "Enabled", "color": "green"}}},
-		{"name": "net.core.bpf_jit_harden", "desc": "Turn on BPF JIT hardening", "values": map[string]map[string]string{"0": {"res": "Disabled", "color": "red"}, "1": {"res": "Partial", "color": "yellow"}, "2": {"res": "Enabled", "color": "green"}}},
-		{"name": "vm.unprivileged_userfaultfd", "desc": "Disable userfaultfd usage", "values": map[string]map[string]string{"1": {"res": "Disabled", "color": "red"}, "0": {"res": "Enabled", "color": "green"}}},
-		{"name": "fs.suid_dumpable", "desc": "Ensure suid binaries can't be dumped", "values": map[string]map[string]string{"2": {"res": "Disabled", "color": "red"}, "1": {"res": "Partial", "color": "yellow"}, "0": {"res": "Enabled", "color": "green"}}},
-	}
+// sysctlChecks are the hardening related sysctls and the verdict for each of
+// their values.
+var sysctlChecks = []map[string]interface{}{
+	{"name": "fs.protected_symlinks", "desc": "Protected symlinks", "values": map[string]sysctlValue{"0": {"Disabled", StatusFail}, "1": {"Enabled", StatusPass}}},
+	{"name": "fs.protected_hardlinks", "desc": "Protected hardlinks", "values": map[string]sysctlValue{"0": {"Disabled", StatusFail}, "1": {"Enabled", StatusPass}}},
+	{"name": "net.ipv4.conf.all.rp_filter", "desc": "Ipv4 reverse path filtering", "values": map[string]sysctlValue{"0": {"Disabled", StatusFail}, "1": {"Enabled", StatusPass}}},
+	{"name": "kernel.yama.ptrace_scope", "desc": "YAMA", "values": map[string]sysctlValue{"0": {"Disabled", StatusFail}, "1": {"Enabled", StatusPass}}},
+	{"name": "kernel.exec-shield", "desc": "Exec Shield", "values": map[string]sysctlValue{"0": {"Disabled", StatusFail}, "1": {"Enabled", StatusPass}}},
+	// Since Linux 5.13, 2 also disables unprivileged BPF but lets an
+	// admin turn it back on; both values deny unprivileged users.
+	{"name": "kernel.unprivileged_bpf_disabled", "desc": "Unprivileged BPF Disabled", "values": map[string]sysctlValue{"0": {"Disabled", StatusFail}, "1": {"Enabled", StatusPass}, "2": {"Enabled", StatusPass}}},
+	{"name": "kernel.randomize_va_space", "desc": "Vanilla Kernel ASLR", "values": map[string]sysctlValue{"0": {"Disabled", StatusFail}, "1": {"Partial", StatusPartial}, "2": {"Enabled", StatusPass}}},
+	{"name": "kernel.dmesg_restrict", "desc": "Dmesg Restrictions", "values": map[string]sysctlValue{"0": {"Disabled", StatusFail}, "1": {"Enabled", StatusPass}}},
+	{"name": "kernel.kptr_restrict", "desc": "Kernel Pointer Restrictions", "values": map[string]sysctlValue{"0": {"Disabled", StatusFail}, "1": {"Partial", StatusPartial}, "2": {"Enabled", StatusPass}}},
+	{"name": "fs.protected_fifos", "desc": "Protected fifos", "values": map[string]sysctlValue{"0": {"Disabled", StatusFail}, "1": {"Partial", StatusPartial}, "2": {"Enabled", StatusPass}}},
+	{"name": "fs.protected_regular", "desc": "Protected regular", "values": map[string]sysctlValue{"0": {"Disabled", StatusFail}, "1": {"Partial", StatusPartial}, "2": {"Enabled", StatusPass}}},
+	{"name": "kernel.perf_event_paranoid", "desc": "Performance events by normal users", "values": map[string]sysctlValue{"-1": {"Disabled", StatusFail}, "0": {"Disabled", StatusFail}, "1": {"Partial", StatusPartial}, "2": {"Enabled", StatusPass}, "3": {"Enabled", StatusPass}}},
+	{"name": "dev.tty.ldisc_autoload", "desc": "Disable Autoload TTY Line Disciplines", "values": map[string]sysctlValue{"1": {"Disabled", StatusFail}, "0": {"Enabled", StatusPass}}},
+	{"name": "dev.tty.legacy_tiocsti", "desc": "Disable Legacy TIOCSTI (breaks screen readers)", "values": map[string]sysctlValue{"1": {"Disabled", StatusFail}, "0": {"Enabled", StatusPass}}},
+	{"name": "kernel.kexec_load_disabled", "desc": "Turn off kexec", "values": map[string]sysctlValue{"0": {"Disabled", StatusFail}, "1": {"Enabled", StatusPass}}},
+	{"name": "net.core.bpf_jit_harden", "desc": "Turn on BPF JIT hardening", "values": map[string]sysctlValue{"0": {"Disabled", StatusFail}, "1": {"Partial", StatusPartial}, "2": {"Enabled", StatusPass}}},
+	{"name": "vm.unprivileged_userfaultfd", "desc": "Disable userfaultfd usage", "values": map[string]sysctlValue{"1": {"Disabled", StatusFail}, "0": {"Enabled", StatusPass}}},
+	{"name": "fs.suid_dumpable", "desc": "Ensure suid binaries can't be dumped", "values": map[string]sysctlValue{"2": {"Disabled", StatusFail}, "1": {"Partial", StatusPartial}, "0": {"Enabled", StatusPass}}},
+}
 
+// SysctlCheck reads the hardening related sysctls of the running system.
+// Sysctls that cannot be read are reported as "Unknown" on Linux and "N/A"
+// elsewhere.
+func SysctlCheck() []KernelResult {
+	var results []KernelResult
 	for _, s := range sysctlChecks {
-		var res []interface{}
-		var colors []interface{}
-		var output string
-		var color string
 		check, _ := sysctl.Get(s["name"].(string))
+		results = append(results, sysctlResult(s, check))
+	}
 
-		values := s["values"].(map[string]map[string]string)
+	return results
+}
 
-		if len(check) == 0 {
-			if runtime.GOOS == "linux" {
-				output = "Unknown"
-			} else {
-				output = "N/A"
-			}
-			color = "italic"
+// sysctlResult returns the verdict of the sysctl check s for the value check,
+// which is empty when the sysctl could not be read.
+func sysctlResult(s map[string]interface{}, check string) KernelResult {
+	res := KernelResult{Name: s["name"].(string), Description: s["desc"].(string), Type: "Sysctl"}
+	values := s["values"].(map[string]sysctlValue)
+
+	if len(check) == 0 {
+		if runtime.GOOS == "linux" {
+			res.Value = "Unknown"
 		} else {
-			output = values[check]["res"]
-			color = values[check]["color"]
-		}
-		res = []interface{}{
-			map[string]interface{}{
-				"name":  s["name"],
-				"value": output,
-				"desc":  s["desc"],
-				"type":  "Sysctl",
-			},
+			res.Value = "N/A"
 		}
-		colors = []interface{}{
-			map[string]interface{}{
-				"name":  s["name"],
-				"value": output,
-				"color": color,
-				"desc":  s["desc"],
-				"type":  "Sysctl",
-			},
-		}
-		Results = append(Results, res...)
-		ColorResults = append(ColorResults, colors...)
+		res.Status = StatusNA
+	} else if value, ok := values[check]; ok {
+		res.Value, res.Status = value.res, value.status
+	} else {
+		res.Value, res.Status = fmt.Sprintf("Unknown (%s)", check), StatusUnknown
 	}
-
-	return Results, ColorResults
+	return res
 }
@@ -5,14 +5,11 @@ import (
 )
 
 func TestSysctlCheck_ReturnsWellFormedOutput(t *testing.T) {
-	results, colors := SysctlCheck()
+	results := SysctlCheck()
 
 	if len(results) == 0 {
 		t.Fatal("SysctlCheck() returned no results")
 	}
-	if len(results) != len(colors) {
-		t.Fatalf("results length %d != colors length %d", len(results), len(colors))
-	}
 
 	validColors := map[string]bool{
 		"green":  true,
@@ -21,33 +18,58 @@ func TestSysctlCheck_ReturnsWellFormedOutput(t *testing.T) {
 		"italic": true,
 	}
 
-	for i, colorEntry := range colors {
-		colorMap, ok := colorEntry.(map[string]interface{})
-		if !ok {
-			t.Errorf("colors[%d] is %T, want map[string]interface{}", i, colorEntry)
-			continue
-		}
-		if color, ok := colorMap["color"].(string); ok {
-			if !validColors[color] {
-				t.Errorf("colors[%d] has unexpected color %q", i, color)
-			}
+	for i, r := range results {
+		if !validColors[r.Color()] {
+			t.Errorf("results[%d] (%s) has unexpected color %q", i, r.Name, r.Color())
 		}
 	}
 }
 
 func TestSysctlCheck_EachResultHasRequiredFields(t *testing.T) {
-	results, _ := SysctlCheck()
+	for i, r := range SysctlCheck() {
+		if r.Name == "" || r.Value == "" || r.Description == "" || r.Type != "Sysctl" {
+			t.Errorf("results[%d] missing fields: %+v", i, r)
+		}
+	}
+}
 
-	for i, resultEntry := range results {
-		m, ok := resultEntry.(map[string]interface{})
-		if !ok {
-			t.Errorf("results[%d] is %T, want map[string]interface{}", i, resultEntry)
-			continue
+// sysctlCheck returns the entry of sysctlChecks for name.
+func sysctlCheck(t *testing.T, name string) map[string]interface{} {
+	t.Helper()
+	for _, s := range sysctlChecks {
+		if s["name"] == name {
+			return s
 		}
-		for _, field := range []string{"name", "value", "desc", "type"} {
-			if _, ok := m[field]; !ok {
-				t.Errorf("results[%d] missing field %q", i, field)
-			}
+	}
+	t.Fatalf("no sysctl check for %s", name)
+	return nil
+}
+
+func TestSysctlResult_UnprivilegedBPF(t *testing.T) {
+	s := sysctlCheck(t, "kernel.unprivileged_bpf_disabled")
+	tests := []struct {
+		value  string
+		want   string
+		status Status
+	}{
+		{"0", "Disabled", StatusFail},
+		{"1", "Enabled", StatusPass},
+		// Linux 5.13+: disabled, but an admin may re-enable it.
+		{"2", "Enabled", StatusPass},
+	}
+	for _, tt := range tests {
+		res := sysctlResult(s, tt.value)
+		if res.Value != tt.want || res.Status != tt.status {
+			t.Errorf("unprivileged_bpf_disabled=%s = %q %s, want %q %s", tt.value, res.Value, res.Status, tt.want, tt.status)
 		}
 	}
 }
+
+func TestSysctlResult_UnmappedValue(t *testing.T) {
+	// A value the table has no verdict for is shown rather than left blank,
+	// and is neither a pass nor a fail.
+	res := sysctlResult(sysctlCheck(t, "kernel.randomize_va_space"), "7")
+	if res.Value != "Unknown (7)" || res.Status != StatusUnknown {
+		t.Errorf("randomize_va_space=7 = %q %s, want \"Unknown (7)\" unknown", res.Value, res.Status)
+	}
+}
@@ -0,0 +1,207 @@
+package checksec
+
+import (
+	"debug/elf"
+	"fmt"
+)
+
+// TextRelocation is a dynamic relocation that makes the loader write to an
+// executable segment.
+type TextRelocation struct {
+	// Offset is the virtual address the relocation writes to.
+	Offset uint64 `json:"offset"`
+	// Type is the relocation type, such as "R_X86_64_64".
+	Type string `json:"type"`
+	// Symbol is the symbol the relocation refers to, if any and known.
+	Symbol string `json:"symbol,omitempty"`
+	// Segment is the executable PT_LOAD segment written to, such as
+	// "LOAD[2]".
+	Segment string `json:"segment"`
+}
+
+func (r TextRelocation) String() string {
+	s := fmt.Sprintf("%s at %#x in %s", r.Type, r.Offset, r.Segment)
+	if r.Symbol != "" {
+		s += " against " + r.Symbol
+	}
+	return s
+}
+
+// TextRelocations is the machine value of the TEXTREL check.
+type TextRelocations struct {
+	// TextRel is true when DT_TEXTREL or DF_TEXTREL is set.
+	TextRel bool `json:"textrel"`
+	// Relocations lists the dynamic relocations that target executable
+	// segments.
+	Relocations []TextRelocation `json:"relocations,omitempty"`
+}
+
+// textrelCheck reports whether the loader has to write to the code of b. The
+// dynamic entries fall back to PT_DYNAMIC, so section-stripped files are
+// covered too.
+func textrelCheck(b *Binary) *Result {
+	if !hasProg(b.File, elf.PT_DYNAMIC) {
+		return &Result{Status: StatusNA, Output: "N/A"}
+	}
+	textrel, flags := b.DynValue(elf.DT_TEXTREL), b.DynValue(elf.DT_FLAGS)
+
+	value := TextRelocations{TextRel: len(textrel) > 0 || len(flags) > 0 && elf.DynFlag(flags[0])&elf.DF_TEXTREL != 0}
+	value.Relocations = textRelocations(b)
+	switch {
+	case value.TextRel:
+		return &Result{Status: StatusFail, Output: "TEXTREL", Value: value}
+	case len(value.Relocations) > 0:
+		// The linker should have set DT_TEXTREL; the loader will fault
+		// on the read-only code instead of relocating it.
+		return &Result{Status: StatusFail, Output: "Unflagged TEXTREL", Value: value}
+	}
+	return &Result{Status: StatusPass, Output: "No TEXTREL", Value: value}
+}
+
+func hasProg(file *elf.File, typ elf.ProgType) bool {
+	for _, prog := range file.Progs {
+		if prog.Type == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// relocTable locates a table of dynamic relocations by its address, size and
+// entry size tags.
+type relocTable struct {
+	addr, size, ent elf.DynTag
+	rela            bool
+}
+
+var relocTables = []relocTable{
+	{elf.DT_RELA, elf.DT_RELASZ, elf.DT_RELAENT, true},
+	{elf.DT_REL, elf.DT_RELSZ, elf.DT_RELENT, false},
+	{elf.DT_JMPREL, elf.DT_PLTRELSZ, elf.DT_NULL, false},
+}
+
+// textRelocations returns the dynamic relocations of b whose target lies in
+// a read-only executable PT_LOAD segment.
+func textRelocations(b *Binary) []TextRelocation {
+	dynValue := func(tag elf.DynTag) uint64 {
+		v := b.DynValue(tag)
+		if len(v) == 0 {
+			return 0
+		}
+		return v[0]
+	}
+	var names []string
+	if symbols, err := b.DynamicSymbols(); err == nil {
+		for _, s := range symbols {
+			names = append(names, s.Name)
+		}
+	}
+
+	is64 := b.File.Class == elf.ELFCLASS64
+	var relocs []TextRelocation
+	for _, table := range relocTables {
+		addr, size := dynValue(table.addr), dynValue(table.size)
+		if addr == 0 || size == 0 {
+			continue
+		}
+		rela := table.rela
+		if table.addr == elf.DT_JMPREL {
+			rela = dynValue(elf.DT_PLTREL) == uint64(elf.DT_RELA)
+		}
+		ent := relocEntSize(is64, rela)
+		data, err := readVirtual(b.File, addr, size)
+		if err != nil {
+			continue
+		}
+		for off := uint64(0); off+ent <= uint64(len(data)); off += ent {
+			var target, info uint64
+			if is64 {
+				target = b.File.ByteOrder.Uint64(data[off:])
+				info = b.File.ByteOrder.Uint64(data[off+8:])
+			} else {
+				target = uint64(b.File.ByteOrder.Uint32(data[off:]))
+				info = uint64(b.File.ByteOrder.Uint32(data[off+4:]))
+			}
+			segment, ok := execSegment(b.File, target)
+			if !ok {
+				continue
+			}
+			typ, sym := info&0xff, info>>8
+			if is64 {
+				typ, sym = info&0xffffffff, info>>32
+			}
+			reloc := TextRelocation{Offset: target, Type: relocTypeName(b.File.Machine, uint32(typ)), Segment: segment}
+			// DynamicSymbols omits the null symbol at index 0.
+			if sym > 0 && sym <= uint64(len(names)) {
+				reloc.Symbol = names[sym-1]
+			}
+			relocs = append(relocs, reloc)
+		}
+	}
+	return relocs
+}
+
+func relocEntSize(is64, rela bool) uint64 {
+	switch {
+	case is64 && rela:
+		return 24
+	case is64:
+		return 16
+	case rela:
+		return 12
+	}
+	return 8
+}
+
+// readVirtual reads size bytes at the virtual address addr from the PT_LOAD
+// segment that maps them.
+func readVirtual(file *elf.File, addr, size uint64) ([]byte, error) {
+	for _, prog := range file.Progs {
+		if prog.Type != elf.PT_LOAD || addr < prog.Vaddr || addr-prog.Vaddr+size > prog.Filesz {
+			continue
+		}
+		data := make([]byte, size)
+		if _, err := prog.ReadAt(data, int64(addr-prog.Vaddr)); err != nil {
+			return nil, err
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("address %#x is not mapped from the file", addr)
+}
+
+// execSegment returns the name of the read-only executable PT_LOAD segment
+// containing addr. Writable and executable segments, such as the PPC32 BSS
+// PLT, need no text relocation and are left to the W^X check.
+func execSegment(file *elf.File, addr uint64) (string, bool) {
+	for i, prog := range file.Progs {
+		if prog.Type == elf.PT_LOAD && prog.Flags&(elf.PF_X|elf.PF_W) == elf.PF_X && addr >= prog.Vaddr && addr-prog.Vaddr < prog.Memsz {
+			return fmt.Sprintf("LOAD[%d]", i), true
+		}
+	}
+	return "", false
+}
+
+// relocTypeName returns the name of a relocation type on machine.
+func relocTypeName(machine elf.Machine, typ uint32) string {
+	switch machine {
+	case elf.EM_X86_64:
+		return elf.R_X86_64(typ).String()
+	case elf.EM_386:
+		return elf.R_386(typ).String()
+	case elf.EM_AARCH64:
+		return elf.R_AARCH64(typ).String()
+	case elf.EM_ARM:
+		return elf.R_ARM(typ).String()
+	case elf.EM_PPC64:
+		return elf.R_PPC64(typ).String()
+	case elf.EM_RISCV:
+		return elf.R_RISCV(typ).String()
+	}
+	return fmt.Sprintf("type %d", typ)
+}
+
+// TextRelocationsOf returns the TextRelocations carried by a TEXTREL result.
+func TextRelocationsOf(r Result) TextRelocations {
+	value, _ := r.Value.(TextRelocations)
+	return value
+}
@@ -0,0 +1,95 @@
+package checksec
+
+import (
+	"debug/elf"
+	"testing"
+)
+
+func TestTextrelCheck_Fixtures(t *testing.T) {
+	tests := []struct {
+		fixture string
+		status  Status
+		output  string
+	}{
+		{"all", StatusPass, "No TEXTREL"},
+		{"dso.so", StatusPass, "No TEXTREL"},
+		{"rel.o", StatusNA, "N/A"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			b, err := OpenBinary(requireFixture(t, tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer b.Close()
+
+			res := textrelCheck(b)
+			if res.Status != tt.status || res.Output != tt.output {
+				t.Errorf("textrelCheck = %s %q, want %s %q", res.Status, res.Output, tt.status, tt.output)
+			}
+			if relocs := TextRelocationsOf(*res).Relocations; len(relocs) != 0 {
+				t.Errorf("unexpected text relocations %v", relocs)
+			}
+		})
+	}
+}
+
+func TestTextRelocationString(t *testing.T) {
+	tests := []struct {
+		reloc TextRelocation
+		want  string
+	}{
+		{TextRelocation{Offset: 0x110b, Type: "R_X86_64_64", Symbol: "x", Segment: "LOAD[1]"}, "R_X86_64_64 at 0x110b in LOAD[1] against x"},
+		{TextRelocation{Offset: 0x2000, Type: "R_386_RELATIVE", Segment: "LOAD[0]"}, "R_386_RELATIVE at 0x2000 in LOAD[0]"},
+	}
+	for _, tt := range tests {
+		if got := tt.reloc.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestRelocTypeName(t *testing.T) {
+	tests := []struct {
+		machine elf.Machine
+		typ     uint32
+		want    string
+	}{
+		{elf.EM_X86_64, uint32(elf.R_X86_64_64), "R_X86_64_64"},
+		{elf.EM_386, uint32(elf.R_386_32), "R_386_32"},
+		{elf.EM_AARCH64, uint32(elf.R_AARCH64_ABS64), "R_AARCH64_ABS64"},
+		{elf.EM_MIPS, 2, "type 2"},
+	}
+	for _, tt := range tests {
+		if got := relocTypeName(tt.machine, tt.typ); got != tt.want {
+			t.Errorf("relocTypeName(%v, %d) = %q, want %q", tt.machine, tt.typ, got, tt.want)
+		}
+	}
+}
+
+func TestExecSegment(t *testing.T) {
+	file := &elf.File{Progs: []*elf.Prog{
+		{ProgHeader: elf.ProgHeader{Type: elf.PT_LOAD, Flags: elf.PF_R, Vaddr: 0, Memsz: 0x1000}},
+		{ProgHeader: elf.ProgHeader{Type: elf.PT_LOAD, Flags: elf.PF_R | elf.PF_X, Vaddr: 0x1000, Memsz: 0x1000}},
+		{ProgHeader: elf.ProgHeader{Type: elf.PT_LOAD, Flags: elf.PF_R | elf.PF_W, Vaddr: 0x3000, Memsz: 0x1000}},
+		{ProgHeader: elf.ProgHeader{Type: elf.PT_LOAD, Flags: elf.PF_R | elf.PF_W | elf.PF_X, Vaddr: 0x5000, Memsz: 0x1000}},
+	}}
+	tests := []struct {
+		addr uint64
+		want string
+		ok   bool
+	}{
+		{0x10, "", false},
+		{0x1000, "LOAD[1]", true},
+		{0x1fff, "LOAD[1]", true},
+		{0x2000, "", false},
+		{0x3010, "", false},
+		// RWX segments are writable already; W^X reports them.
+		{0x5010, "", false},
+	}
+	for _, tt := range tests {
+		if got, ok := execSegment(file, tt.addr); got != tt.want || ok != tt.ok {
+			t.Errorf("execSegment(%#x) = %q, %v; want %q, %v", tt.addr, got, ok, tt.want, tt.ok)
+		}
+	}
+}
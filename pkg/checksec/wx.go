@@ -0,0 +1,91 @@
+package checksec
+
+import (
+	"debug/elf"
+	"fmt"
+	"strings"
+)
+
+// WXRegion is a segment or section that is both writable and executable.
+type WXRegion struct {
+	// Name is the section name, or the segment type and index such as
+	// "LOAD[2]".
+	Name   string `json:"name"`
+	Offset uint64 `json:"offset"`
+	Size   uint64 `json:"size"`
+}
+
+func (r WXRegion) String() string {
+	return fmt.Sprintf("%s off=%#x size=%#x", r.Name, r.Offset, r.Size)
+}
+
+// WXRegions is the machine value of the W^X check.
+type WXRegions struct {
+	// Segments lists the PT_LOAD segments mapped PF_W|PF_X.
+	Segments []WXRegion `json:"segments,omitempty"`
+	// Sections lists the sections flagged SHF_WRITE|SHF_EXECINSTR.
+	Sections []WXRegion `json:"sections,omitempty"`
+}
+
+// String lists the segments and then the sections, for example
+// "LOAD[2] off=0x2000 size=0x1000; .wxdata off=0x2000 size=0x40".
+func (w WXRegions) String() string {
+	var regions []string
+	for _, r := range append(w.Segments, w.Sections...) {
+		regions = append(regions, r.String())
+	}
+	return strings.Join(regions, "; ")
+}
+
+// wxCheck reports the regions of b that are writable and executable at once.
+// Unlike NX it looks at every loadable segment, not just the stack.
+func wxCheck(b *Binary) *Result {
+	var regions WXRegions
+	for i, prog := range b.File.Progs {
+		if prog.Type == elf.PT_LOAD && prog.Flags&(elf.PF_W|elf.PF_X) == elf.PF_W|elf.PF_X {
+			regions.Segments = append(regions.Segments, WXRegion{
+				Name:   fmt.Sprintf("LOAD[%d]", i),
+				Offset: prog.Off,
+				Size:   prog.Memsz,
+			})
+		}
+	}
+	for _, section := range b.File.Sections {
+		if section.Flags&(elf.SHF_WRITE|elf.SHF_EXECINSTR) == elf.SHF_WRITE|elf.SHF_EXECINSTR {
+			regions.Sections = append(regions.Sections, WXRegion{
+				Name:   section.Name,
+				Offset: section.Offset,
+				Size:   section.Size,
+			})
+		}
+	}
+
+	if len(b.File.Progs) == 0 && len(b.File.Sections) == 0 {
+		return &Result{Status: StatusNA, Output: "N/A"}
+	}
+	if len(regions.Segments) == 0 && len(regions.Sections) == 0 {
+		return &Result{Status: StatusPass, Output: "W^X enforced", Value: regions}
+	}
+
+	var parts []string
+	if n := len(regions.Segments); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d RWX segment%s", n, plural(n)))
+	}
+	if n := len(regions.Sections); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d WX section%s", n, plural(n)))
+	}
+	return &Result{Status: StatusFail, Output: strings.Join(parts, ", "), Value: regions}
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
+
+// WXRegionsOf returns the WXRegions carried by a W^X result.
+func WXRegionsOf(r Result) WXRegions {
+	regions, _ := r.Value.(WXRegions)
+	return regions
+}
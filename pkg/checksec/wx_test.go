@@ -0,0 +1,110 @@
+package checksec
+
+import (
+	"debug/elf"
+	"reflect"
+	"testing"
+)
+
+func TestWXCheck(t *testing.T) {
+	load := func(flags elf.ProgFlag, off, size uint64) *elf.Prog {
+		return &elf.Prog{ProgHeader: elf.ProgHeader{Type: elf.PT_LOAD, Flags: flags, Off: off, Memsz: size}}
+	}
+	section := func(name string, flags elf.SectionFlag, off, size uint64) *elf.Section {
+		return &elf.Section{SectionHeader: elf.SectionHeader{Name: name, Flags: flags, Offset: off, Size: size}}
+	}
+
+	tests := []struct {
+		name     string
+		file     *elf.File
+		status   Status
+		output   string
+		regions  WXRegions
+		rendered string
+	}{
+		{
+			name:   "no headers",
+			file:   &elf.File{},
+			status: StatusNA,
+			output: "N/A",
+		},
+		{
+			name: "separate text and data",
+			file: &elf.File{
+				Progs: []*elf.Prog{load(elf.PF_R|elf.PF_X, 0, 0x1000), load(elf.PF_R|elf.PF_W, 0x1000, 0x200)},
+				Sections: []*elf.Section{
+					section(".text", elf.SHF_ALLOC|elf.SHF_EXECINSTR, 0x100, 0x80),
+					section(".data", elf.SHF_ALLOC|elf.SHF_WRITE, 0x1000, 0x20),
+				},
+			},
+			status: StatusPass,
+			output: "W^X enforced",
+		},
+		{
+			name: "rwx segment",
+			file: &elf.File{Progs: []*elf.Prog{
+				{ProgHeader: elf.ProgHeader{Type: elf.PT_GNU_STACK, Flags: elf.PF_R | elf.PF_W | elf.PF_X}},
+				load(elf.PF_R|elf.PF_W|elf.PF_X, 0x2000, 0x1800),
+			}},
+			status:   StatusFail,
+			output:   "1 RWX segment",
+			regions:  WXRegions{Segments: []WXRegion{{Name: "LOAD[1]", Offset: 0x2000, Size: 0x1800}}},
+			rendered: "LOAD[1] off=0x2000 size=0x1800",
+		},
+		{
+			name: "wx sections in an object file",
+			file: &elf.File{Sections: []*elf.Section{
+				section(".wxdata", elf.SHF_ALLOC|elf.SHF_WRITE|elf.SHF_EXECINSTR, 0x40, 0x10),
+				section(".trampolines", elf.SHF_WRITE|elf.SHF_EXECINSTR, 0x50, 0x8),
+			}},
+			status: StatusFail,
+			output: "2 WX sections",
+			regions: WXRegions{Sections: []WXRegion{
+				{Name: ".wxdata", Offset: 0x40, Size: 0x10},
+				{Name: ".trampolines", Offset: 0x50, Size: 0x8},
+			}},
+			rendered: ".wxdata off=0x40 size=0x10; .trampolines off=0x50 size=0x8",
+		},
+		{
+			name: "segment and section",
+			file: &elf.File{
+				Progs:    []*elf.Prog{load(elf.PF_R|elf.PF_W|elf.PF_X, 0, 0x3000)},
+				Sections: []*elf.Section{section(".wxdata", elf.SHF_ALLOC|elf.SHF_WRITE|elf.SHF_EXECINSTR, 0x2010, 0x4)},
+			},
+			status: StatusFail,
+			output: "1 RWX segment, 1 WX section",
+			regions: WXRegions{
+				Segments: []WXRegion{{Name: "LOAD[0]", Offset: 0, Size: 0x3000}},
+				Sections: []WXRegion{{Name: ".wxdata", Offset: 0x2010, Size: 0x4}},
+			},
+			rendered: "LOAD[0] off=0x0 size=0x3000; .wxdata off=0x2010 size=0x4",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := wxCheck(&Binary{File: tt.file})
+			if res.Status != tt.status || res.Output != tt.output {
+				t.Errorf("wxCheck = %s %q, want %s %q", res.Status, res.Output, tt.status, tt.output)
+			}
+			regions := WXRegionsOf(*res)
+			if !reflect.DeepEqual(regions, tt.regions) {
+				t.Errorf("regions = %+v, want %+v", regions, tt.regions)
+			}
+			if s := regions.String(); s != tt.rendered {
+				t.Errorf("String() = %q, want %q", s, tt.rendered)
+			}
+		})
+	}
+}
+
+func TestWXCheck_Fixture(t *testing.T) {
+	b, err := OpenBinary(requireFixture(t, "all"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	if res := wxCheck(b); res.Status != StatusPass {
+		t.Errorf("wxCheck(all) = %+v, want pass", res)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/slimm609/checksec/v3/pkg/checksec"
 )
 
 const fixtureELF = "../../tests/binaries/output/none"
@@ -34,24 +36,46 @@ func TestCheckIfElf_RealAndNonElf(t *testing.T) {
 	}
 }
 
+func TestCheckIfElf_ClosesFile(t *testing.T) {
+	// dir and procAll call CheckIfElf on every file; each call used to
+	// leave its descriptor open until the process hit its limit.
+	fds, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		t.Skipf("cannot list open files: %v", err)
+	}
+	self, err := os.Executable()
+	if err != nil {
+		t.Skipf("cannot find the test binary: %v", err)
+	}
+	for range 100 {
+		if !CheckIfElf(self) {
+			t.Fatalf("CheckIfElf(%q) = false, want true", self)
+		}
+	}
+	after, _ := os.ReadDir("/proc/self/fd")
+	if len(after) > len(fds) {
+		t.Errorf("open files went from %d to %d", len(fds), len(after))
+	}
+}
+
 func TestCheckFileExists_Positive(t *testing.T) {
 	requireFixtureBytes(t)
-	if !CheckFileExists(fixtureELF) {
-		t.Errorf("CheckFileExists(%q) = false, want true", fixtureELF)
+	if err := CheckFileExists(fixtureELF); err != nil {
+		t.Errorf("CheckFileExists(%q) = %v, want nil", fixtureELF, err)
 	}
 }
 
 func TestCheckDirExists_Positive(t *testing.T) {
-	if !CheckDirExists("../../tests/binaries/output") {
-		t.Errorf("CheckDirExists(fixture dir) = false, want true")
+	if err := CheckDirExists("../../tests/binaries/output"); err != nil {
+		t.Errorf("CheckDirExists(fixture dir) = %v, want nil", err)
 	}
 }
 
 func TestGetBinary_Positive(t *testing.T) {
 	requireFixtureBytes(t)
-	b := GetBinary(fixtureELF)
-	if b == nil {
-		t.Fatal("GetBinary returned nil")
+	b, err := GetBinary(fixtureELF)
+	if err != nil {
+		t.Fatalf("GetBinary: %v", err)
 	}
 	defer b.Close()
 	if len(b.Sections) == 0 && len(b.Progs) == 0 {
@@ -71,7 +95,10 @@ func TestGetAllFilesFromDir_Recursive(t *testing.T) {
 		t.Fatalf("write: %v", err)
 	}
 
-	got := GetAllFilesFromDir(dir, true)
+	got, err := GetAllFilesFromDir(dir, true)
+	if err != nil {
+		t.Fatalf("GetAllFilesFromDir: %v", err)
+	}
 	found := false
 	for _, p := range got {
 		if filepath.Base(p) == "app" {
@@ -83,36 +110,35 @@ func TestGetAllFilesFromDir_Recursive(t *testing.T) {
 	}
 }
 
-// TestRunFileChecks_RealFixture exercises the real check wrappers (relroFn,
-// canaryFn, ... fortifyFn) end-to-end against a committed fixture, rather than
-// the stubbed hooks used by TestRunFileChecks_UsesHooksAndAggregates.
-func TestRunFileChecks_RealFixture(t *testing.T) {
+// TestScanFile_RealFixture exercises the real checks end-to-end against a
+// committed fixture, as the file command does.
+func TestScanFile_RealFixture(t *testing.T) {
 	requireFixtureBytes(t)
 
-	data, color := RunFileChecks(fixtureELF, "")
-	if len(data) == 0 || len(color) == 0 {
-		t.Fatal("RunFileChecks returned empty results")
-	}
-
-	// The aggregated data must be valid JSON-serialisable and contain checks.
-	raw, err := json.Marshal(data)
+	var scanner checksec.Scanner
+	report, err := scanner.ScanFile(fixtureELF)
 	if err != nil {
-		t.Fatalf("marshal data: %v", err)
-	}
-	var decoded []map[string]interface{}
-	if err := json.Unmarshal(raw, &decoded); err != nil {
-		t.Fatalf("unmarshal data: %v", err)
+		t.Fatalf("ScanFile: %v", err)
 	}
-	if len(decoded) != 1 {
-		t.Fatalf("expected 1 result entry, got %d", len(decoded))
-	}
-	checks, ok := decoded[0]["checks"].(map[string]interface{})
-	if !ok {
-		t.Fatalf("missing checks map in %#v", decoded[0])
-	}
-	for _, key := range []string{"relro", "canary", "nx", "pie", "rpath", "runpath", "symbols"} {
-		if _, present := checks[key]; !present {
-			t.Errorf("checks map missing %q", key)
+
+	// Every check must have produced a verdict.
+	results := map[string]checksec.Result{
+		"relro":   report.Relro,
+		"canary":  report.Canary,
+		"nx":      report.NX,
+		"pie":     report.PIE,
+		"rpath":   report.RPath,
+		"runpath": report.RunPath,
+		"symbols": report.Symbols,
+	}
+	for key, res := range results {
+		if res.Output == "" || res.Status == "" {
+			t.Errorf("%s result is empty: %+v", key, res)
 		}
 	}
+
+	// The report must serialise to JSON.
+	if _, err := json.Marshal(report); err != nil {
+		t.Fatalf("marshal report: %v", err)
+	}
 }
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"encoding/csv"
+	"os"
+
+	"github.com/slimm609/checksec/v3/pkg/checksec"
+)
+
+// kernelCSVHeader and fortifyCSVHeader are the header rows of the csv and tsv
+// formats. They use the same names as the json keys.
+var (
+	kernelCSVHeader  = []string{"name", "desc", "value", "type"}
+	fortifyCSVHeader = []string{"name", "fortified", "fortifyable", "fortify_source", "noFortify", "libcSupport", "numLibcFunc", "numFileFunc"}
+)
+
+// isDelimited reports whether outputFormat is csv or tsv.
+func isDelimited(outputFormat string) bool {
+	return outputFormat == "csv" || outputFormat == "tsv"
+}
+
+// newDelimitedWriter returns a writer to stdout for the csv or tsv format.
+// Fields containing the separator, quotes or newlines are quoted.
+func newDelimitedWriter(outputFormat string) *csv.Writer {
+	w := csv.NewWriter(os.Stdout)
+	if outputFormat == "tsv" {
+		w.Comma = '\t'
+	}
+	return w
+}
+
+// fileCSVHeader returns the header row of file reports: the key of every
+// registered column in registry order, followed by name. The table shows the
+// same columns in the same order, less the detail columns marked NoTable.
+func fileCSVHeader() []string {
+	var header []string
+	for _, c := range checksec.Checks() {
+		for _, col := range checksec.Columns(c) {
+			header = append(header, col.Key)
+		}
+	}
+	return append(header, "name")
+}
+
+// fileCSVRecord returns the row of a file report, matching fileCSVHeader.
+func fileCSVRecord(report *checksec.FileReport) []string {
+	var record []string
+	for _, c := range reportCells(report) {
+		record = append(record, c.Text)
+	}
+	return append(record, report.Name)
+}
+
+// writeDelimited writes the header, unless noHeader is set, and records in
+// the csv or tsv format.
+func writeDelimited(outputFormat string, noHeader bool, header []string, records ...[]string) {
+	w := newDelimitedWriter(outputFormat)
+	if !noHeader {
+		w.Write(header)
+	}
+	w.WriteAll(records)
+}
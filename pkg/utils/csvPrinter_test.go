@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"encoding/csv"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/slimm609/checksec/v3/pkg/checksec"
+)
+
+func readDelimited(t *testing.T, out string, comma rune) [][]string {
+	t.Helper()
+	r := csv.NewReader(strings.NewReader(out))
+	r.Comma = comma
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("parse %q: %v", out, err)
+	}
+	return records
+}
+
+func TestFileWriter_CSVAndTSV(t *testing.T) {
+	odd := sampleReport()
+	odd.Name = "dir, with \"quotes\"\tand tabs"
+
+	for _, tt := range []struct {
+		format string
+		comma  rune
+	}{{"csv", ','}, {"tsv", '\t'}} {
+		t.Run(tt.format, func(t *testing.T) {
+			out := captureOutput(t, func() {
+				w := NewFileWriter(tt.format, false, false)
+				w.Write(sampleReport())
+				w.Write(odd)
+				w.Close()
+			})
+			records := readDelimited(t, out, tt.comma)
+			if len(records) != 3 {
+				t.Fatalf("got %d records, want header and 2 rows:\n%s", len(records), out)
+			}
+			header := records[0]
+			if !slices.Equal(header, fileCSVHeader()) || header[len(header)-1] != "name" {
+				t.Errorf("header = %v", header)
+			}
+			row := map[string]string{}
+			for i, key := range header {
+				row[key] = records[1][i]
+			}
+			if row["relro"] != "Full RELRO" || row["fortified"] != "2" || row["name"] != "bin" {
+				t.Errorf("row = %v", row)
+			}
+			if got := records[2][slices.Index(header, "name")]; got != odd.Name {
+				t.Errorf("quoted name = %q, want %q", got, odd.Name)
+			}
+		})
+	}
+}
+
+func TestFileCSVHeader_FollowsTable(t *testing.T) {
+	// The table columns are the csv columns without the detail columns.
+	var table []string
+	for _, c := range checksec.Checks() {
+		for _, col := range checksec.Columns(c) {
+			if !col.NoTable {
+				table = append(table, col.Key)
+			}
+		}
+	}
+	table = append(table, "name")
+	var got []string
+	for _, key := range fileCSVHeader() {
+		if slices.Contains(table, key) {
+			got = append(got, key)
+		}
+	}
+	if !slices.Equal(got, table) {
+		t.Errorf("csv columns in the table = %v, want table order %v", got, table)
+	}
+}
+
+func TestFileWriter_CSVNoHeader(t *testing.T) {
+	out := captureOutput(t, func() { FilePrinter("csv", []*checksec.FileReport{sampleReport()}, false, true) })
+	records := readDelimited(t, out, ',')
+	if len(records) != 1 || records[0][0] != "Full RELRO" {
+		t.Errorf("records = %v, want a single row without header", records)
+	}
+}
+
+func TestKernelPrinter_CSV(t *testing.T) {
+	results := []checksec.KernelResult{
+		{Name: "CONF", Description: "Harden, str/mem", Type: "Kernel Config", Value: "Enabled", Status: checksec.StatusPass},
+	}
+	out := captureOutput(t, func() { KernelPrinter("csv", results, false, false) })
+	records := readDelimited(t, out, ',')
+	want := [][]string{kernelCSVHeader, {"CONF", "Harden, str/mem", "Enabled", "Kernel Config"}}
+	if !slices.EqualFunc(records, want, slices.Equal) {
+		t.Errorf("records = %v, want %v", records, want)
+	}
+}
+
+func TestFortifyPrinter_TSV(t *testing.T) {
+	res := &checksec.Result{
+		Status: checksec.StatusPass,
+		Output: "Yes",
+		Value: checksec.FortifyDetails{
+			Fortified: 2, Fortifiable: 3, NoFortify: 1, NumLibcFunc: 10, NumFileFunc: 5,
+			LibcSupport: checksec.Result{Status: checksec.StatusPass, Output: "Yes"},
+		},
+	}
+	out := captureOutput(t, func() { FortifyPrinter("tsv", "/bin/x", res, false, false) })
+	records := readDelimited(t, out, '\t')
+	want := [][]string{fortifyCSVHeader, {"/bin/x", "2", "3", "Yes", "1", "Yes", "10", "5"}}
+	if !slices.EqualFunc(records, want, slices.Equal) {
+		t.Errorf("records = %v, want %v", records, want)
+	}
+}
@@ -1,133 +1,386 @@
 package utils
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
-	"log"
+	"sort"
+	"strings"
 
+	"github.com/slimm609/checksec/v3/pkg/checksec"
 	"github.com/slimm609/checksec/v3/pkg/output"
 	"sigs.k8s.io/yaml"
 )
 
+// SecurityCheck is the serialised form of a checksec.FileReport used by the
+// json, yaml and xml output formats.
 type SecurityCheck struct {
-	Name   string `json:"name"`
-	Checks struct {
-		Canary        string `json:"canary"`
-		Fortified     string `json:"fortified"`
-		FortifyAble   string `json:"fortifyable"`
-		FortifySource string `json:"fortify_source"`
-		NX            string `json:"nx"`
-		PIE           string `json:"pie"`
-		Relro         string `json:"relro"`
-		RPath         string `json:"rpath"`
-		RunPath       string `json:"runpath"`
-		Symbols       string `json:"symbols"`
-		SafeStack     string `json:"safestack"`
-	} `json:"checks"`
-}
-
-type SecurityCheckColor struct {
-	Name   string `json:"name"`
-	Checks struct {
-		Canary             string `json:"canary"`
-		CanaryColor        string `json:"canaryColor"`
-		Cfi                string `json:"cfi"`
-		CfiColor           string `json:"cfiColor"`
-		Fortified          string `json:"fortified"`
-		FortifyAble        string `json:"fortifyable"`
-		FortifySource      string `json:"fortify_source"`
-		FortifySourceColor string `json:"fortify_sourceColor"`
-		NX                 string `json:"nx"`
-		NXColor            string `json:"nxColor"`
-		PIE                string `json:"pie"`
-		PIEColor           string `json:"pieColor"`
-		Relro              string `json:"relro"`
-		RelroColor         string `json:"relroColor"`
-		RPath              string `json:"rpath"`
-		RPathColor         string `json:"rpathColor"`
-		RunPath            string `json:"runpath"`
-		RunPathColor       string `json:"runpathColor"`
-		Symbols            string `json:"symbols"`
-		SymbolsColor       string `json:"symbolsColor"`
-		SafeStack          string `json:"safestack"`
-		SafeStackColor     string `json:"safestackColor"`
-	} `json:"checks"`
-}
-
-func FilePrinter(outputFormat string, data interface{}, colors interface{}, noBanner bool, noHeader bool) {
-
-	formatted, err := json.MarshalIndent(data, "", "  ")
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
+	Name   string      `json:"name"`
+	Checks CheckValues `json:"checks"`
+}
+
+// CheckValue is a single rendered output column.
+type CheckValue struct {
+	Key   string
+	Value string
+	// Data is the structured value of the column, if it has one. JSON and
+	// YAML carry it instead of Value.
+	Data any
+}
+
+// CheckValues holds the rendered output columns of a report in registry
+// order. It serialises as an object keyed by column.
+type CheckValues []CheckValue
+
+// Get returns the value of the column with the given key.
+func (c CheckValues) Get(key string) string {
+	for _, v := range c {
+		if v.Key == key {
+			return v.Value
+		}
 	}
-	formattedcolor, err := json.MarshalIndent(colors, "", "  ")
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
+	return ""
+}
+
+// MarshalJSON writes the columns as a JSON object, preserving their order.
+func (c CheckValues) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, v := range c {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(v.Key)
+		if err != nil {
+			return nil, err
+		}
+		var value []byte
+		if v.Data != nil {
+			value, err = json.Marshal(v.Data)
+		} else {
+			value, err = json.Marshal(v.Value)
+		}
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON reads a JSON object of columns, ordered by key. Columns that
+// are not strings are decoded into Data.
+func (c *CheckValues) UnmarshalJSON(data []byte) error {
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*c = (*c)[:0]
+	for key, raw := range values {
+		v := CheckValue{Key: key}
+		if err := json.Unmarshal(raw, &v.Value); err != nil {
+			if err := json.Unmarshal(raw, &v.Data); err != nil {
+				return err
+			}
+		}
+		*c = append(*c, v)
+	}
+	sort.Slice(*c, func(i, j int) bool { return (*c)[i].Key < (*c)[j].Key })
+	return nil
+}
+
+// MarshalXML writes each column as a child element named by its key. XML
+// carries the rendered text of every column.
+func (c CheckValues) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, v := range c {
+		if err := e.EncodeElement(v.Value, xml.StartElement{Name: xml.Name{Local: v.Key}}); err != nil {
+			return err
+		}
 	}
-	var securityChecks []SecurityCheck
-	// Unmarshal JSON data
-	if err := json.Unmarshal([]byte(formatted), &securityChecks); err != nil {
-		fmt.Println("Error:", err)
+	return e.EncodeToken(start.End())
+}
+
+// cell is one rendered output column of a report.
+type cell struct {
+	checksec.Column
+	Text  string
+	Color string
+	Spans []checksec.Span
+	Data  any
+}
+
+// reportCells renders the columns of every registered check for a report. The
+// first column of each check is colored by its status.
+func reportCells(report *checksec.FileReport) []cell {
+	var cells []cell
+	for _, c := range checksec.Checks() {
+		res, _ := report.Result(c.ID())
+		for i, col := range checksec.Columns(c) {
+			text, color := res.Output, res.Color()
+			if col.Value != nil {
+				text = col.Value(res)
+			}
+			if i > 0 {
+				color = "unset"
+			}
+			var spans []checksec.Span
+			if col.Spans != nil {
+				spans = col.Spans(res)
+			}
+			var data any
+			if col.Data != nil {
+				data = col.Data(res)
+			}
+			cells = append(cells, cell{Column: col, Text: text, Color: color, Spans: spans, Data: data})
+		}
+	}
+	return cells
+}
+
+// newSecurityCheck flattens a report into the human readable output strings.
+func newSecurityCheck(report *checksec.FileReport) SecurityCheck {
+	check := SecurityCheck{Name: report.Name}
+	for _, c := range reportCells(report) {
+		check.Checks = append(check.Checks, CheckValue{Key: c.Key, Value: c.Text, Data: c.Data})
+	}
+	return check
+}
+
+// columnWidths are the table widths of the built-in columns; other columns
+// use defaultColumnWidth.
+var columnWidths = map[string]int{
+	"relro":          24,
+	"canary":         26,
+	"cfi":            26,
+	"mte":            24,
+	"nx":             22,
+	"pie":            24,
+	"wx":             22,
+	"textrel":        19,
+	"rpath":          19,
+	"runpath":        21,
+	"symbols":        24,
+	"safestack":      24,
+	"fortify_source": 19,
+	"fortified":      20,
+	"fortifyable":    25,
+	"dangerous":      11,
+	"annobin":        26,
+	"compile_flags":  26,
+}
+
+const defaultColumnWidth = 24
+
+// tableCell pads text to the column width before coloring it, so the color
+// escape codes do not count towards the width.
+func tableCell(key, text, color string) string {
+	return tableSpans(key, text, []checksec.Span{{Text: text, Color: color}})
+}
+
+// tableSpans colors each span of text and pads the result to the column
+// width of text.
+func tableSpans(key, text string, spans []checksec.Span) string {
+	width, ok := columnWidths[key]
+	if !ok {
+		width = defaultColumnWidth
+	}
+	var b strings.Builder
+	for _, s := range spans {
+		b.WriteString(output.ColorPrinter(s.Text, s.Color))
+	}
+	if pad := width - len(text); pad > 0 {
+		return b.String() + strings.Repeat(" ", pad)
+	}
+	return b.String() + " "
+}
+
+// FileWriter prints file reports in the given output format. The table,
+// ndjson, csv and tsv formats print each report as soon as it is written; json, yaml, xml,
+// sarif and junit are documents, so they are buffered until Close.
+type FileWriter struct {
+	// Verbose prints the details of each check below the binary's table
+	// row.
+	Verbose bool
+
+	outputFormat string
+	noBanner     bool
+	noHeader     bool
+	started      bool
+	buffered     []*checksec.FileReport
+	delimited    *csv.Writer
+}
+
+// NewFileWriter returns a FileWriter for the output format. Callers must
+// Close it once every report has been written.
+func NewFileWriter(outputFormat string, noBanner bool, noHeader bool) *FileWriter {
+	return &FileWriter{outputFormat: outputFormat, noBanner: noBanner, noHeader: noHeader}
+}
+
+// streaming reports whether reports are printed as they are written.
+func (w *FileWriter) streaming() bool {
+	switch w.outputFormat {
+	case "json", "yaml", "xml", "sarif", "junit":
+		return false
+	}
+	return true
+}
+
+// start prints the banner and table header before the first row.
+func (w *FileWriter) start() {
+	if w.started {
+		return
+	}
+	w.started = true
+	if isDelimited(w.outputFormat) {
+		w.delimited = newDelimitedWriter(w.outputFormat)
+		if !w.noHeader {
+			w.delimited.Write(fileCSVHeader())
+			w.delimited.Flush()
+		}
 		return
 	}
+	if !w.streaming() || w.outputFormat == "ndjson" {
+		return
+	}
+	output.PrintLogo(w.noBanner)
+	if !w.noHeader {
+		for _, c := range checksec.Checks() {
+			for _, col := range checksec.Columns(c) {
+				if !col.NoTable {
+					fmt.Print(tableCell(col.Key, col.Header, "unset"))
+				}
+			}
+		}
+		fmt.Println(output.ColorPrinter("Name", "unset"))
+	}
+}
+
+// Write prints or buffers a single report.
+func (w *FileWriter) Write(report *checksec.FileReport) {
+	w.start()
+	switch {
+	case !w.streaming():
+		w.buffered = append(w.buffered, report)
+	case w.outputFormat == "ndjson":
+		line, err := json.Marshal(newSecurityCheck(report))
+		if err != nil {
+			fmt.Printf("err: %v\n", err)
+			return
+		}
+		fmt.Println(string(line))
+	case w.delimited != nil:
+		w.delimited.Write(fileCSVRecord(report))
+		w.delimited.Flush()
+	default:
+		for _, c := range reportCells(report) {
+			if c.NoTable {
+				continue
+			}
+			if c.Spans != nil {
+				fmt.Print(tableSpans(c.Key, c.Text, c.Spans))
+				continue
+			}
+			fmt.Print(tableCell(c.Key, c.Text, c.Color))
+		}
+		fmt.Println(output.ColorPrinter(report.Name, "unset"))
+		if w.Verbose {
+			printHiddenColumns(report)
+			printDetails(report)
+		}
+	}
+}
+
+// printHiddenColumns prints the non-empty columns left out of the table row,
+// indented and prefixed with their header.
+func printHiddenColumns(report *checksec.FileReport) {
+	for _, c := range reportCells(report) {
+		if !c.NoTable || c.Text == "" {
+			continue
+		}
+		spans := c.Spans
+		if spans == nil {
+			spans = []checksec.Span{{Text: c.Text, Color: c.Color}}
+		}
+		fmt.Printf("    %s: ", c.Header)
+		for _, s := range spans {
+			fmt.Print(output.ColorPrinter(s.Text, s.Color))
+		}
+		fmt.Println()
+	}
+}
+
+// printDetails prints the details of every check of a report, indented and
+// prefixed with the check's header.
+func printDetails(report *checksec.FileReport) {
+	for _, c := range checksec.Checks() {
+		res, _ := report.Result(c.ID())
+		for _, line := range checksec.Details(c, res) {
+			fmt.Printf("    %s: %s\n", c.Header(), line)
+		}
+	}
+}
 
-	if outputFormat == "yaml" {
+// Close prints the buffered document formats.
+func (w *FileWriter) Close() {
+	w.start()
+	if w.streaming() {
+		return
+	}
+
+	if w.outputFormat == "sarif" {
+		formatted, err := json.MarshalIndent(newSarifLog(w.buffered), "", "  ")
+		if err != nil {
+			fmt.Printf("err: %v\n", err)
+			return
+		}
+		fmt.Println(string(formatted))
+		return
+	}
+	if w.outputFormat == "junit" {
+		printJUnit(newFileJUnit(w.buffered))
+		return
+	}
+
+	securityChecks := make([]SecurityCheck, 0, len(w.buffered))
+	for _, report := range w.buffered {
+		securityChecks = append(securityChecks, newSecurityCheck(report))
+	}
+	formatted, err := json.MarshalIndent(securityChecks, "", "  ")
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+	}
+
+	if w.outputFormat == "yaml" {
 		yamlResponse, err := yaml.JSONToYAML(formatted)
 		if err != nil {
 			fmt.Printf("err: %v\n", err)
 		}
 		fmt.Println(string(yamlResponse))
-	} else if outputFormat == "json" {
+	} else if w.outputFormat == "json" {
 		fmt.Println(string(formatted))
-	} else if outputFormat == "xml" {
+	} else {
 		xmlData, err := xml.MarshalIndent(securityChecks, "", "  ")
 		if err != nil {
-			log.Fatal(err)
+			fmt.Printf("err: %v\n", err)
+			return
 		}
 		fmt.Println(string(xmlData))
-	} else {
-		output.PrintLogo(noBanner)
-		var securityChecksColors []SecurityCheckColor
+	}
+}
 
-		// Unmarshal JSON data
-		if err = json.Unmarshal([]byte(formattedcolor), &securityChecksColors); err != nil {
-			fmt.Println("Error:", err)
-			return
-		}
-		if !noHeader {
-			fmt.Printf("%-24s%-26s%-26s%-22s%-24s%-19s%-21s%-24s%-24s%-19s%-20s%-25s%-40s\n",
-				output.ColorPrinter("RELRO", "unset"),
-				output.ColorPrinter("Stack Canary", "unset"),
-				output.ColorPrinter("CFI", "unset"),
-				output.ColorPrinter("NX", "unset"),
-				output.ColorPrinter("PIE", "unset"),
-				output.ColorPrinter("RPATH", "unset"),
-				output.ColorPrinter("RUNPATH", "unset"),
-				output.ColorPrinter("Symbols", "unset"),
-				output.ColorPrinter("SafeStack", "unset"),
-				output.ColorPrinter("FORTIFY", "unset"),
-				output.ColorPrinter("Fortified", "unset"),
-				output.ColorPrinter("Fortifiable", "unset"),
-				output.ColorPrinter("Name", "unset"),
-			)
-		}
-		for _, check := range securityChecksColors {
-			fmt.Printf("%-25s%-27s%-27s%-23s%-25s%-20s%-22s%-25s%-25s%-20s%-20s%-25s%-40s\n",
-				output.ColorPrinter(check.Checks.Relro, check.Checks.RelroColor),
-				output.ColorPrinter(check.Checks.Canary, check.Checks.CanaryColor),
-				output.ColorPrinter(check.Checks.Cfi, check.Checks.CfiColor),
-				output.ColorPrinter(check.Checks.NX, check.Checks.NXColor),
-				output.ColorPrinter(check.Checks.PIE, check.Checks.PIEColor),
-				output.ColorPrinter(check.Checks.RPath, check.Checks.RPathColor),
-				output.ColorPrinter(check.Checks.RunPath, check.Checks.RunPathColor),
-				output.ColorPrinter(check.Checks.Symbols, check.Checks.SymbolsColor),
-				output.ColorPrinter(check.Checks.SafeStack, check.Checks.SafeStackColor),
-				output.ColorPrinter(check.Checks.FortifySource, check.Checks.FortifySourceColor),
-				output.ColorPrinter(check.Checks.Fortified, "unset"),
-				output.ColorPrinter(check.Checks.FortifyAble, "unset"),
-				output.ColorPrinter(check.Name, "unset"),
-			)
-		}
+// FilePrinter prints a complete set of reports.
+func FilePrinter(outputFormat string, reports []*checksec.FileReport, noBanner bool, noHeader bool) {
+	w := NewFileWriter(outputFormat, noBanner, noHeader)
+	for _, report := range reports {
+		w.Write(report)
 	}
+	w.Close()
 }
@@ -2,69 +2,198 @@ package utils
 
 import (
 	"encoding/json"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/slimm609/checksec/v3/pkg/checksec"
 )
 
-func TestFilePrinter_JSON_YAML_XML_AndTable(t *testing.T) {
-	// prepare minimal data matching expected schema
-	data := []interface{}{
-		map[string]any{
-			"name": "bin",
-			"checks": map[string]any{
-				"relro":          "Full RELRO",
-				"canary":         "Canary Found",
-				"nx":             "NX enabled",
-				"pie":            "PIE Enabled",
-				"rpath":          "No RPATH",
-				"runpath":        "No RUNPATH",
-				"symbols":        "0 symbols",
-				"safestack":      "No SafeStack Found",
-				"fortify_source": "Yes",
-				"fortified":      "2",
-				"fortifyable":    "3",
-			},
-		},
+func sampleReport() *checksec.FileReport {
+	pass := func(output string) checksec.Result {
+		return checksec.Result{Status: checksec.StatusPass, Output: output}
 	}
-	colors := []interface{}{
-		map[string]any{
-			"name": "bin",
-			"checks": map[string]any{
-				"relro": "Full RELRO", "relroColor": "green",
-				"canary": "Canary Found", "canaryColor": "green",
-				"nx": "NX enabled", "nxColor": "green",
-				"pie": "PIE Enabled", "pieColor": "green",
-				"rpath": "No RPATH", "rpathColor": "green",
-				"runpath": "No RUNPATH", "runpathColor": "green",
-				"symbols": "0 symbols", "symbolsColor": "green",
-				"safestack": "No SafeStack Found", "safestackColor": "red",
-				"fortify_source": "Yes", "fortify_sourceColor": "green",
-				"fortified":   "2",
-				"fortifyable": "3",
-			},
+	return &checksec.FileReport{
+		Name:      "bin",
+		Relro:     pass("Full RELRO"),
+		Canary:    pass("Canary Found"),
+		Cfi:       checksec.Result{Status: checksec.StatusUnknown, Output: "Unknown"},
+		NX:        pass("NX enabled"),
+		PIE:       pass("PIE Enabled"),
+		RPath:     pass("No RPATH"),
+		RunPath:   pass("No RUNPATH"),
+		Symbols:   pass("No Symbols"),
+		SafeStack: checksec.Result{Status: checksec.StatusFail, Output: "No SafeStack Found"},
+		Fortify: checksec.Result{
+			Status: checksec.StatusPass,
+			Output: "Yes",
+			Value:  checksec.FortifyDetails{Fortified: 2, Fortifiable: 3},
 		},
 	}
+}
+
+func TestFilePrinter_JSON_YAML_XML_AndTable(t *testing.T) {
+	reports := []*checksec.FileReport{sampleReport()}
 
 	// JSON
-	out := captureOutput(t, func() { FilePrinter("json", data, colors, true, true) })
+	out := captureOutput(t, func() { FilePrinter("json", reports, true, true) })
 	if !json.Valid([]byte(out)) {
 		t.Fatalf("expected valid JSON output, got: %q", out)
 	}
+	var decoded []SecurityCheck
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(decoded) != 1 || decoded[0].Checks.Get("relro") != "Full RELRO" || decoded[0].Checks.Get("fortified") != "2" || decoded[0].Checks.Get("fortifyable") != "3" {
+		t.Fatalf("unexpected JSON schema: %s", out)
+	}
 
 	// YAML
-	out = captureOutput(t, func() { FilePrinter("yaml", data, colors, true, true) })
+	out = captureOutput(t, func() { FilePrinter("yaml", reports, true, true) })
 	if len(out) == 0 {
 		t.Fatalf("expected YAML output")
 	}
 
 	// XML
-	out = captureOutput(t, func() { FilePrinter("xml", data, colors, true, true) })
-	if len(out) == 0 || out[0] != '<' {
+	out = captureOutput(t, func() { FilePrinter("xml", reports, true, true) })
+	if len(out) == 0 || out[0] != '<' || !strings.Contains(out, "<relro>Full RELRO</relro>") {
 		t.Fatalf("expected XML output, got %q", out)
 	}
 
 	// Table
-	out = captureOutput(t, func() { FilePrinter("table", data, colors, true, false) })
-	if len(out) == 0 {
-		t.Fatalf("expected table output")
+	out = captureOutput(t, func() { FilePrinter("table", reports, true, false) })
+	if !strings.Contains(out, "Full RELRO") || !strings.Contains(out, "RELRO") {
+		t.Fatalf("expected table output, got %q", out)
+	}
+}
+
+func TestFileWriter_NDJSON(t *testing.T) {
+	second := sampleReport()
+	second.Name = "other"
+
+	out := captureOutput(t, func() {
+		w := NewFileWriter("ndjson", false, false)
+		w.Write(sampleReport())
+		w.Write(second)
+		w.Close()
+	})
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one line per report, got %q", out)
+	}
+	for i, want := range []string{"bin", "other"} {
+		var check SecurityCheck
+		if err := json.Unmarshal([]byte(lines[i]), &check); err != nil {
+			t.Fatalf("line %d is not JSON: %v", i, err)
+		}
+		if check.Name != want || check.Checks.Get("relro") != "Full RELRO" {
+			t.Errorf("line %d = %+v", i, check)
+		}
+	}
+}
+
+func TestFileWriter_TableStreamsRows(t *testing.T) {
+	// Rows must be printed by Write, not deferred to Close.
+	out := captureOutput(t, func() {
+		w := NewFileWriter("table", true, false)
+		w.Write(sampleReport())
+	})
+	if strings.Count(out, "Name") != 1 || strings.Count(out, "Full RELRO") != 1 {
+		t.Fatalf("expected the header and row before Close, got %q", out)
+	}
+}
+
+func TestFileWriter_EmptyJSON(t *testing.T) {
+	out := captureOutput(t, func() { NewFileWriter("json", true, true).Close() })
+	if strings.TrimSpace(out) != "[]" {
+		t.Fatalf("expected an empty JSON array, got %q", out)
+	}
+}
+
+func TestFileWriter_VerboseDetails(t *testing.T) {
+	report := sampleReport()
+	report.TextRel = checksec.Result{
+		Status: checksec.StatusFail,
+		Output: "TEXTREL",
+		Value: checksec.TextRelocations{TextRel: true, Relocations: []checksec.TextRelocation{
+			{Offset: 0x110b, Type: "R_X86_64_64", Symbol: "x", Segment: "LOAD[1]"},
+		}},
+	}
+	detail := "    TEXTREL: R_X86_64_64 at 0x110b in LOAD[1] against x\n"
+
+	for _, verbose := range []bool{false, true} {
+		out := captureOutput(t, func() {
+			w := NewFileWriter("table", true, true)
+			w.Verbose = verbose
+			w.Write(report)
+			w.Close()
+		})
+		if got := strings.Contains(out, detail); got != verbose {
+			t.Errorf("verbose=%v: details printed = %v in %q", verbose, got, out)
+		}
+	}
+}
+
+func TestTableSpans_PadsByText(t *testing.T) {
+	spans := []checksec.Span{{Text: "/opt", Color: "unset"}, {Text: ":", Color: "unset"}, {Text: "lib", Color: "red"}}
+	got := tableSpans("rpath_paths", "/opt:lib", spans)
+	if !strings.HasPrefix(got, "/opt") || !strings.Contains(got, "lib") || !strings.HasSuffix(got, strings.Repeat(" ", 24-len("/opt:lib"))) {
+		t.Errorf("tableSpans = %q", got)
+	}
+}
+
+func TestFileWriter_NoTableColumns(t *testing.T) {
+	report := sampleReport()
+	report.Dangerous = checksec.Result{Status: checksec.StatusPartial, Output: "2", Value: []string{"gets", "strcpy"}}
+
+	table := captureOutput(t, func() { FilePrinter("table", []*checksec.FileReport{report}, true, false) })
+	if strings.Contains(table, "Dangerous Functions") || strings.Contains(table, "gets,strcpy") {
+		t.Errorf("table shows the function list: %q", table)
+	}
+	if !strings.Contains(table, "Dangerous") {
+		t.Errorf("table lacks the count column: %q", table)
+	}
+
+	out := captureOutput(t, func() { FilePrinter("json", []*checksec.FileReport{report}, true, true) })
+	var decoded []SecurityCheck
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	var functions any
+	for _, v := range decoded[0].Checks {
+		if v.Key == "dangerous_functions" {
+			functions = v.Data
+		}
+	}
+	if !reflect.DeepEqual(functions, []any{"gets", "strcpy"}) {
+		t.Errorf("dangerous_functions = %#v, want the list as an array", functions)
+	}
+	if !strings.Contains(out, `"rpath_paths": []`) {
+		t.Errorf("empty list columns are not empty arrays: %s", out)
+	}
+
+	csv := captureOutput(t, func() { FilePrinter("csv", []*checksec.FileReport{report}, true, true) })
+	if !strings.Contains(csv, `"gets,strcpy"`) {
+		t.Errorf("csv lacks the joined list: %q", csv)
+	}
+}
+
+func TestFileWriter_VerboseHiddenColumns(t *testing.T) {
+	report := sampleReport()
+	report.Canary.Value = checksec.CanaryStackChkFail
+
+	for _, verbose := range []bool{false, true} {
+		out := captureOutput(t, func() {
+			w := NewFileWriter("table", true, false)
+			w.Verbose = verbose
+			w.Write(report)
+			w.Close()
+		})
+		if strings.Contains(out, "Canary Mechanism  ") || strings.Contains(out, "Unhardened Units") {
+			t.Errorf("verbose=%v: table row shows a detail column: %q", verbose, out)
+		}
+		if got := strings.Contains(out, "    Canary Mechanism: __stack_chk_fail\n"); got != verbose {
+			t.Errorf("verbose=%v: detail column printed = %v in %q", verbose, got, out)
+		}
 	}
 }
@@ -3,11 +3,10 @@ package utils
 import (
 	"os"
 	"path/filepath"
-	"runtime"
+	"strings"
 	"testing"
 )
 
-// Test CheckElfExists behavior without causing os.Exit by using a child process
 func TestCheckElfExists_PositivePath(t *testing.T) {
 	// Create a temporary file; it is not an ELF, so we bypass the actual checks by overriding hooks
 	dir := t.TempDir()
@@ -16,35 +15,44 @@ func TestCheckElfExists_PositivePath(t *testing.T) {
 		t.Fatalf("write temp: %v", err)
 	}
 
-	// override functions to avoid os.Exit for negative paths
 	origCheckFile, origCheckElf := checkFileExistsFn, checkIfElfFn
 	defer func() { checkFileExistsFn, checkIfElfFn = origCheckFile, origCheckElf }()
-	checkFileExistsFn = func(string) bool { return true }
+	checkFileExistsFn = func(string) error { return nil }
 	checkIfElfFn = func(string) bool { return true }
 
-	if !CheckElfExists(f) {
-		t.Fatalf("expected true")
+	if err := CheckElfExists(f); err != nil {
+		t.Fatalf("expected no error, got %v", err)
 	}
 }
 
-func TestGetAllFilesFromDir(t *testing.T) {
-	if runtime.GOOS == "windows" {
-		t.Skip("path permissions differ on windows")
-	}
+func TestFileChecks_Errors(t *testing.T) {
 	dir := t.TempDir()
-	// files: one elf-like, one not
-	good := filepath.Join(dir, "elf1")
-	bad := filepath.Join(dir, "text1")
-	_ = os.WriteFile(good, []byte("dummy"), 0o644)
-	_ = os.WriteFile(bad, []byte("dummy"), 0o644)
-
-	// stub CheckIfElf to only accept good
-	orig := checkIfElfFn
-	defer func() { checkIfElfFn = orig }()
-	checkIfElfFn = func(path string) bool { return filepath.Base(path) == "elf1" }
+	plain := filepath.Join(dir, "plain")
+	if err := os.WriteFile(plain, []byte("plain text"), 0o644); err != nil {
+		t.Fatalf("write temp: %v", err)
+	}
+	missing := filepath.Join(dir, "does-not-exist")
 
-	got := GetAllFilesFromDir(dir, false)
-	if len(got) != 1 || filepath.Base(got[0]) != "elf1" {
-		t.Fatalf("expected only elf1, got %#v", got)
+	tests := []struct {
+		name string
+		fn   func() error
+		want string
+	}{
+		{"file missing", func() error { return CheckFileExists(missing) }, "File not found"},
+		{"dir missing", func() error { return CheckDirExists(missing) }, "Directory not found"},
+		{"dir is file", func() error { return CheckDirExists(plain) }, "is not a Directory"},
+		{"elf missing", func() error { return CheckElfExists(missing) }, "File not found"},
+		{"elf not elf", func() error { return CheckElfExists(plain) }, "is not an ELF file"},
+		{"binary not elf", func() error { _, err := GetBinary(plain); return err }, "ELF"},
+		{"dir without binaries", func() error { _, err := GetAllFilesFromDir(dir, false); return err }, "no binary files found"},
+		{"dir list missing", func() error { _, err := GetAllFilesFromDir(missing, false); return err }, "Directory not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.fn()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
 	}
 }
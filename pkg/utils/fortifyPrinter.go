@@ -4,14 +4,17 @@ import (
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
-	"log"
+	"slices"
+	"strconv"
+	"strings"
 
+	"github.com/slimm609/checksec/v3/pkg/checksec"
 	"github.com/slimm609/checksec/v3/pkg/output"
 	"sigs.k8s.io/yaml"
 )
 
-// FortifyCheck struct for non-colored data,
-// keeping a dedicated struct allows conversion without removing the colors
+// FortifyCheck is the serialised form of a checksec.Fortify result used by the
+// json, yaml and xml output formats.
 type FortifyCheck struct {
 	Name   string `json:"name"`
 	Checks struct {
@@ -22,42 +25,40 @@ type FortifyCheck struct {
 		LibcSupport   string `json:"libcSupport"`
 		NumLibcFunc   string `json:"numLibcFunc"`
 		NumFileFunc   string `json:"numFileFunc"`
+		// The function names behind Fortified, NoFortify and NumLibcFunc.
+		FortifiedFuncs   []string `json:"fortifiedFuncs" xml:"FortifiedFuncs>Func"`
+		UnfortifiedFuncs []string `json:"unfortifiedFuncs" xml:"UnfortifiedFuncs>Func"`
+		LibcFuncs        []string `json:"libcFuncs" xml:"LibcFuncs>Func"`
 	} `json:"checks"`
 }
 
-// FortifyCheckColor struct for colored data
-type FortifyCheckColor struct {
-	Name   string `json:"name"`
-	Checks struct {
-		Fortified          string `json:"fortified"`
-		FortifyAble        string `json:"fortifyable"`
-		FortifySource      string `json:"fortify_source"`
-		FortifySourceColor string `json:"fortify_sourceColor"`
-		NoFortify          string `json:"noFortify"`
-		LibcSupport        string `json:"libcSupport"`
-		LibcSupportColor   string `json:"libcSupportColor"`
-		NumLibcFunc        string `json:"numLibcFunc"`
-		NumFileFunc        string `json:"numFileFunc"`
-	} `json:"checks"`
+// newFortifyCheck flattens a fortify result into the human readable output strings.
+func newFortifyCheck(name string, fortify *checksec.Result) FortifyCheck {
+	details := checksec.FortifyDetailsOf(*fortify)
+	var check FortifyCheck
+	check.Name = name
+	check.Checks.Fortified = strconv.Itoa(details.Fortified)
+	check.Checks.FortifyAble = strconv.Itoa(details.Fortifiable)
+	check.Checks.FortifySource = fortify.Output
+	check.Checks.NoFortify = strconv.Itoa(details.NoFortify)
+	check.Checks.LibcSupport = details.LibcSupport.Output
+	check.Checks.NumLibcFunc = strconv.Itoa(details.NumLibcFunc)
+	check.Checks.NumFileFunc = strconv.Itoa(details.NumFileFunc)
+	// Empty lists serialise as [] rather than null.
+	check.Checks.FortifiedFuncs = append([]string{}, details.FortifiedFuncs...)
+	check.Checks.UnfortifiedFuncs = append([]string{}, details.UnfortifiedFuncs...)
+	check.Checks.LibcFuncs = append([]string{}, details.LibcFuncs...)
+	return check
 }
 
 // FortifyPrinter - Print the output from FortifyFile function
-func FortifyPrinter(outputFormat string, data interface{}, colors interface{}, noBanner bool, noHeader bool) {
+func FortifyPrinter(outputFormat string, name string, fortify *checksec.Result, noBanner bool, noHeader bool) {
 
-	formatted, err := json.MarshalIndent(data, "", "  ")
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
-	}
-	formattedcolor, err := json.MarshalIndent(colors, "", "  ")
+	fortifyChecks := []FortifyCheck{newFortifyCheck(name, fortify)}
+	formatted, err := json.MarshalIndent(fortifyChecks, "", "  ")
 	if err != nil {
 		fmt.Printf("err: %v\n", err)
 	}
-	var fortifyChecks []FortifyCheck
-	// Unmarshal JSON data
-	if err := json.Unmarshal([]byte(formatted), &fortifyChecks); err != nil {
-		fmt.Println("Error:", err)
-		return
-	}
 
 	if outputFormat == "yaml" {
 		yamlResponse, err := yaml.JSONToYAML(formatted)
@@ -67,35 +68,60 @@ func FortifyPrinter(outputFormat string, data interface{}, colors interface{}, n
 		fmt.Println(string(yamlResponse))
 	} else if outputFormat == "json" {
 		fmt.Println(string(formatted))
+	} else if outputFormat == "ndjson" {
+		line, err := json.Marshal(fortifyChecks[0])
+		if err != nil {
+			fmt.Printf("err: %v\n", err)
+		}
+		fmt.Println(string(line))
+	} else if isDelimited(outputFormat) {
+		check := fortifyChecks[0]
+		writeDelimited(outputFormat, noHeader, fortifyCSVHeader, []string{
+			check.Name,
+			check.Checks.Fortified,
+			check.Checks.FortifyAble,
+			check.Checks.FortifySource,
+			check.Checks.NoFortify,
+			check.Checks.LibcSupport,
+			check.Checks.NumLibcFunc,
+			check.Checks.NumFileFunc,
+		})
 	} else if outputFormat == "xml" {
 		xmlData, err := xml.MarshalIndent(fortifyChecks, "", "  ")
 		if err != nil {
-			log.Fatal(err)
+			fmt.Printf("err: %v\n", err)
+			return
 		}
 		fmt.Println(string(xmlData))
 	} else {
 		output.PrintLogo(noBanner)
-		var fortifyChecksColors []FortifyCheckColor
+		check := fortifyChecks[0]
+		fmt.Printf("* FORTIFY_SOURCE support available (libc): %s\n", output.ColorPrinter(check.Checks.LibcSupport, checksec.FortifyDetailsOf(*fortify).LibcSupport.Color()))
+		fmt.Printf("* Binary compiled with FORTIFY_SOURCE support: %s\n\n", output.ColorPrinter(check.Checks.FortifySource, fortify.Color()))
+		fmt.Println("------ EXECUTABLE-FILE ------- | -------- LIBC --------")
+		fmt.Println("Fortifiable library functions  | Checked function names")
+		fmt.Println(strings.Repeat("-", 55))
+		printFortifyBreakdown(check)
+		fmt.Printf("\n%s\n", output.ColorPrinter("SUMMARY", "green"))
+		fmt.Printf("* Number of checked functions in libc                : %s\n", output.ColorPrinter(check.Checks.NumLibcFunc, "unset"))
+		fmt.Printf("* Total number of library functions in the executable: %s\n", output.ColorPrinter(check.Checks.NumFileFunc, "unset"))
+		fmt.Printf("* Number of Fortifiable functions in the executable  : %s\n", output.ColorPrinter(check.Checks.FortifyAble, "unset"))
+		fmt.Printf("* Number of checked functions in the executable      : %s\n", output.ColorPrinter(check.Checks.Fortified, "green"))
+		fmt.Printf("* Number of unchecked functions in the executable    : %s\n", output.ColorPrinter(check.Checks.NoFortify, "red"))
+	}
+}
 
-		// Unmarshal JSON data
-		if err = json.Unmarshal([]byte(formattedcolor), &fortifyChecksColors); err != nil {
-			fmt.Println("Error:", err)
-			return
+// printFortifyBreakdown prints, in libc order, every fortifiable function the
+// binary calls next to the checked libc function it corresponds to: red when
+// it calls the unprotected function, green when it calls the checked one.
+func printFortifyBreakdown(check FortifyCheck) {
+	for _, chk := range check.Checks.LibcFuncs {
+		base := strings.TrimSuffix(strings.TrimPrefix(chk, "__"), "_chk")
+		if slices.Contains(check.Checks.UnfortifiedFuncs, base) {
+			fmt.Printf("%s | %s\n", output.ColorPrinter(fmt.Sprintf("%-30s", base), "red"), chk)
 		}
-
-		for _, check := range fortifyChecksColors {
-			fmt.Printf("* FORTIFY_SOURCE support available (libc): %s\n", output.ColorPrinter(check.Checks.LibcSupport, check.Checks.LibcSupportColor))
-			fmt.Printf("* Binary compiled with FORTIFY_SOURCE support: %s\n\n", output.ColorPrinter(check.Checks.FortifySource, check.Checks.FortifySourceColor))
-			fmt.Println("------ EXECUTABLE-FILE ------- | -------- LIBC --------")
-			fmt.Println("Fortifiable library functions  | Checked function names")
-			// TODO: add function breakdown
-			fmt.Println("Coming Soon")
-			fmt.Printf("\n%s\n", output.ColorPrinter("SUMMARY", "green"))
-			fmt.Printf("* Number of checked functions in libc                : %s\n", output.ColorPrinter(check.Checks.NumLibcFunc, "unset"))
-			fmt.Printf("* Total number of library functions in the executable: %s\n", output.ColorPrinter(check.Checks.NumFileFunc, "unset"))
-			fmt.Printf("* Number of Fortifiable functions in the executable  : %s\n", output.ColorPrinter(check.Checks.FortifyAble, "unset"))
-			fmt.Printf("* Number of checked functions in the executable      : %s\n", output.ColorPrinter(check.Checks.Fortified, "green"))
-			fmt.Printf("* Number of unchecked functions in the executable    : %s\n", output.ColorPrinter(check.Checks.NoFortify, "red"))
+		if slices.Contains(check.Checks.FortifiedFuncs, chk) {
+			fmt.Printf("%s | %s\n", output.ColorPrinter(fmt.Sprintf("%-30s", chk), "green"), chk)
 		}
 	}
 }
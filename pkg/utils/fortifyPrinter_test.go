@@ -2,56 +2,69 @@ package utils
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
+
+	"github.com/slimm609/checksec/v3/pkg/checksec"
 )
 
 func TestFortifyPrinter_AllFormats(t *testing.T) {
-	data := []interface{}{
-		map[string]any{
-			"name": "bin",
-			"checks": map[string]any{
-				"fortified":      "1",
-				"fortifyable":    "2",
-				"fortify_source": "Yes",
-				"noFortify":      "1",
-				"libcSupport":    "Yes",
-				"numLibcFunc":    "1",
-				"numFileFunc":    "3",
-			},
-		},
-	}
-	colors := []interface{}{
-		map[string]any{
-			"name": "bin",
-			"checks": map[string]any{
-				"Fortified":     "1",
-				"FortifyAble":   "2",
-				"FortifySource": "Yes", "FortifySourceColor": "green",
-				"NoFortify":   "1",
-				"LibcSupport": "Yes", "LibcSupportColor": "green",
-				"NumLibcFunc": "1",
-				"NumFileFunc": "3",
-			},
+	fortify := &checksec.Result{
+		Status: checksec.StatusPass,
+		Output: "Yes",
+		Value: checksec.FortifyDetails{
+			LibcSupport:      checksec.Result{Status: checksec.StatusPass, Output: "Yes", Value: true},
+			Fortified:        1,
+			Fortifiable:      2,
+			NoFortify:        1,
+			NumLibcFunc:      1,
+			NumFileFunc:      3,
+			FortifiedFuncs:   []string{"__printf_chk"},
+			UnfortifiedFuncs: []string{"strcpy"},
+			LibcFuncs:        []string{"__printf_chk", "__strcpy_chk"},
 		},
 	}
 
-	out := captureOutput(t, func() { FortifyPrinter("json", data, colors, true, true) })
+	out := captureOutput(t, func() { FortifyPrinter("json", "bin", fortify, true, true) })
 	if !json.Valid([]byte(out)) {
 		t.Fatalf("expected JSON, got %q", out)
 	}
+	var decoded []FortifyCheck
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(decoded) != 1 || decoded[0].Checks.NumFileFunc != "3" || decoded[0].Checks.LibcSupport != "Yes" {
+		t.Fatalf("unexpected JSON schema: %s", out)
+	}
+	if got := decoded[0].Checks.UnfortifiedFuncs; len(got) != 1 || got[0] != "strcpy" {
+		t.Fatalf("unfortifiedFuncs = %v, want [strcpy]", got)
+	}
 
-	out = captureOutput(t, func() { FortifyPrinter("yaml", data, colors, true, true) })
+	out = captureOutput(t, func() { FortifyPrinter("ndjson", "bin", fortify, true, true) })
+	var line FortifyCheck
+	if err := json.Unmarshal([]byte(out), &line); err != nil || line.Name != "bin" {
+		t.Fatalf("expected one NDJSON line, got %q", out)
+	}
+
+	out = captureOutput(t, func() { FortifyPrinter("yaml", "bin", fortify, true, true) })
 	if len(out) == 0 {
 		t.Fatalf("expected YAML output")
 	}
 
-	out = captureOutput(t, func() { FortifyPrinter("xml", data, colors, true, true) })
+	out = captureOutput(t, func() { FortifyPrinter("xml", "bin", fortify, true, true) })
 	if len(out) == 0 || out[0] != '<' {
 		t.Fatalf("expected XML output")
 	}
 
-	out = captureOutput(t, func() { FortifyPrinter("table", data, colors, true, false) })
-	if len(out) == 0 {
-		t.Fatalf("expected table output")
+	out = captureOutput(t, func() { FortifyPrinter("xml", "bin", fortify, true, true) })
+	if !strings.Contains(out, "<UnfortifiedFuncs>") || !strings.Contains(out, "<Func>strcpy</Func>") {
+		t.Fatalf("expected function lists in XML, got %q", out)
+	}
+
+	out = captureOutput(t, func() { FortifyPrinter("table", "bin", fortify, true, false) })
+	for _, row := range []string{"__printf_chk                   | __printf_chk", "strcpy                         | __strcpy_chk"} {
+		if !strings.Contains(out, row) {
+			t.Fatalf("expected row %q in table output, got %q", row, out)
+		}
 	}
 }
@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"github.com/slimm609/checksec/v3/pkg/checksec"
+)
+
+// JUnitTestSuites is the root of a JUnit XML report.
+type JUnitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []JUnitTestSuite `xml:"testsuite"`
+}
+
+// JUnitTestSuite groups the checks of one binary, or of one kernel check
+// type.
+type JUnitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []JUnitTestCase `xml:"testcase"`
+}
+
+// JUnitTestCase is a single mitigation check.
+type JUnitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *JUnitMessage `xml:"failure,omitempty"`
+	Error     *JUnitMessage `xml:"error,omitempty"`
+	Skipped   *JUnitMessage `xml:"skipped,omitempty"`
+}
+
+// JUnitMessage is the failure, error or skip reason of a test case. Text
+// repeats Message so renderers that only show the body still show it.
+type JUnitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// add appends a test case for a check result to the suite. Failed and
+// partial mitigations fail, checks that could not run are errors, and checks
+// that do not apply or cannot be decided are skipped.
+func (s *JUnitTestSuite) add(className, name, typ string, status checksec.Status, output string) {
+	tc := JUnitTestCase{ClassName: className, Name: name}
+	msg := &JUnitMessage{Message: output, Type: typ, Text: output}
+	switch status {
+	case checksec.StatusFail, checksec.StatusPartial:
+		tc.Failure = msg
+		s.Failures++
+	case checksec.StatusError:
+		tc.Error = msg
+		s.Errors++
+	case checksec.StatusNA, checksec.StatusUnknown:
+		tc.Skipped = &JUnitMessage{Message: output}
+		s.Skipped++
+	}
+	s.Tests++
+	s.Cases = append(s.Cases, tc)
+}
+
+// newJUnitTestSuites totals the counts of suites.
+func newJUnitTestSuites(suites []JUnitTestSuite) JUnitTestSuites {
+	root := JUnitTestSuites{Name: "checksec", Suites: suites}
+	for _, s := range suites {
+		root.Tests += s.Tests
+		root.Failures += s.Failures
+		root.Errors += s.Errors
+		root.Skipped += s.Skipped
+	}
+	return root
+}
+
+// newFileJUnit converts reports to a JUnit report with one suite per binary
+// and one test case per registered check.
+func newFileJUnit(reports []*checksec.FileReport) JUnitTestSuites {
+	suites := make([]JUnitTestSuite, 0, len(reports))
+	for _, report := range reports {
+		suite := JUnitTestSuite{Name: report.Name}
+		for _, c := range checksec.Checks() {
+			res, _ := report.Result(c.ID())
+			suite.add(report.Name, c.Header(), c.ID(), res.Status, res.Output)
+		}
+		suites = append(suites, suite)
+	}
+	return newJUnitTestSuites(suites)
+}
+
+// newKernelJUnit converts kernel results to a JUnit report with one suite per
+// check type, in order of first appearance.
+func newKernelJUnit(results []checksec.KernelResult) JUnitTestSuites {
+	var suites []JUnitTestSuite
+	index := map[string]int{}
+	for _, r := range results {
+		i, ok := index[r.Type]
+		if !ok {
+			i = len(suites)
+			index[r.Type] = i
+			suites = append(suites, JUnitTestSuite{Name: r.Type})
+		}
+		suites[i].add(r.Type, r.Name, r.Type, r.Status, r.Value)
+	}
+	return newJUnitTestSuites(suites)
+}
+
+// printJUnit prints a JUnit report as an XML document.
+func printJUnit(report JUnitTestSuites) {
+	xmlData, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+		return
+	}
+	fmt.Println(xml.Header + string(xmlData))
+}
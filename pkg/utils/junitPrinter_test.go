@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/slimm609/checksec/v3/pkg/checksec"
+)
+
+func TestFilePrinter_JUnit(t *testing.T) {
+	report := sampleReport()
+	report.PIE = checksec.Result{Status: checksec.StatusError, Output: "Error checking PIE"}
+	report.NX = checksec.Result{Status: checksec.StatusNA, Output: "N/A"}
+
+	out := captureOutput(t, func() { FilePrinter("junit", []*checksec.FileReport{report}, false, false) })
+	if !strings.HasPrefix(out, xml.Header) {
+		t.Fatalf("missing XML header: %q", out)
+	}
+	var suites JUnitTestSuites
+	if err := xml.Unmarshal([]byte(out), &suites); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, out)
+	}
+	if len(suites.Suites) != 1 || suites.Suites[0].Name != "bin" {
+		t.Fatalf("suites = %+v, want one suite for bin", suites.Suites)
+	}
+	suite := suites.Suites[0]
+	if suite.Tests != len(checksec.Checks()) || suite.Failures != 1 || suite.Errors != 1 || suite.Skipped != 2 {
+		t.Errorf("suite counts = %d/%d/%d/%d, want %d tests, 1 failure, 1 error, 2 skipped",
+			suite.Tests, suite.Failures, suite.Errors, suite.Skipped, len(checksec.Checks()))
+	}
+	if suites.Tests != suite.Tests || suites.Failures != suite.Failures {
+		t.Errorf("root counts %d/%d do not match the suite", suites.Tests, suites.Failures)
+	}
+
+	cases := map[string]JUnitTestCase{}
+	for _, tc := range suite.Cases {
+		if tc.ClassName != "bin" {
+			t.Errorf("%s classname = %q, want bin", tc.Name, tc.ClassName)
+		}
+		cases[tc.Name] = tc
+	}
+	if f := cases["SafeStack"].Failure; f == nil || f.Message != "No SafeStack Found" || f.Text != "No SafeStack Found" {
+		t.Errorf("SafeStack failure = %+v", f)
+	}
+	if cases["PIE"].Error == nil || cases["NX"].Skipped == nil || cases["CFI"].Skipped == nil {
+		t.Errorf("PIE/NX/CFI = %+v / %+v / %+v", cases["PIE"], cases["NX"], cases["CFI"])
+	}
+	if tc := cases["RELRO"]; tc.Failure != nil || tc.Error != nil || tc.Skipped != nil {
+		t.Errorf("passing RELRO = %+v", tc)
+	}
+}
+
+func TestKernelPrinter_JUnit(t *testing.T) {
+	results := []checksec.KernelResult{
+		{Name: "CONFIG_A", Description: "a", Type: "Kernel Config", Value: "Enabled", Status: checksec.StatusPass},
+		{Name: "fs.x", Description: "x", Type: "Sysctl", Value: "Disabled", Status: checksec.StatusFail},
+		{Name: "CONFIG_B", Description: "b", Type: "Kernel Config", Value: "Disabled", Status: checksec.StatusFail},
+	}
+	out := captureOutput(t, func() { KernelPrinter("junit", results, true, true) })
+	var suites JUnitTestSuites
+	if err := xml.Unmarshal([]byte(out), &suites); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, out)
+	}
+	if len(suites.Suites) != 2 || suites.Suites[0].Name != "Kernel Config" || suites.Suites[1].Name != "Sysctl" {
+		t.Fatalf("suites = %+v, want Kernel Config and Sysctl", suites.Suites)
+	}
+	kernel := suites.Suites[0]
+	if kernel.Tests != 2 || kernel.Failures != 1 || kernel.Cases[1].Name != "CONFIG_B" || kernel.Cases[1].Failure.Message != "Disabled" {
+		t.Errorf("kernel suite = %+v", kernel)
+	}
+	if suites.Tests != 3 || suites.Failures != 2 {
+		t.Errorf("root counts = %d/%d, want 3/2", suites.Tests, suites.Failures)
+	}
+}
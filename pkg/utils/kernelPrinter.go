@@ -4,20 +4,14 @@ import (
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
-	"log"
 
+	"github.com/slimm609/checksec/v3/pkg/checksec"
 	"github.com/slimm609/checksec/v3/pkg/output"
 	"sigs.k8s.io/yaml"
 )
 
-type KernelCheckColor struct {
-	Name        string `json:"name"`
-	Description string `json:"desc"`
-	Color       string `json:"color"`
-	Value       string `json:"value"`
-	CheckType   string `json:"type"`
-}
-
+// KernelCheck is the serialised form of a checksec.KernelResult used by the
+// json, ndjson, yaml and xml output formats.
 type KernelCheck struct {
 	Name        string `json:"name"`
 	Description string `json:"desc"`
@@ -25,32 +19,18 @@ type KernelCheck struct {
 	CheckType   string `json:"type"`
 }
 
-func KernelPrinter(outputFormat string, kernel any, kernelColors any, noBanner bool, noHeader bool) {
+func KernelPrinter(outputFormat string, results []checksec.KernelResult, noBanner bool, noHeader bool) {
 
-	formattedKernel, err := json.MarshalIndent(kernel, "", "  ")
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
+	checks := make([]KernelCheck, 0, len(results))
+	for _, r := range results {
+		checks = append(checks, newKernelCheck(r))
 	}
 
-	formattedKernelColors, err := json.MarshalIndent(kernelColors, "", "  ")
+	formattedKernel, err := json.MarshalIndent(checks, "", "  ")
 	if err != nil {
 		fmt.Printf("err: %v\n", err)
 	}
 
-	var KernelCheck []KernelCheck
-	// Unmarshal JSON data
-	if err := json.Unmarshal([]byte(formattedKernel), &KernelCheck); err != nil {
-		fmt.Println("Error:", err)
-		return
-	}
-
-	var KernelCheckColor []KernelCheckColor
-	// Unmarshal JSON data
-	if err := json.Unmarshal([]byte(formattedKernelColors), &KernelCheckColor); err != nil {
-		fmt.Println("Error:", err)
-		return
-	}
-
 	if outputFormat == "yaml" {
 		yamlResponse, err := yaml.JSONToYAML(formattedKernel)
 		if err != nil {
@@ -59,10 +39,28 @@ func KernelPrinter(outputFormat string, kernel any, kernelColors any, noBanner b
 		fmt.Println(string(yamlResponse))
 	} else if outputFormat == "json" {
 		fmt.Println(string(formattedKernel))
+	} else if outputFormat == "ndjson" {
+		for _, check := range checks {
+			line, err := json.Marshal(check)
+			if err != nil {
+				fmt.Printf("err: %v\n", err)
+				continue
+			}
+			fmt.Println(string(line))
+		}
+	} else if isDelimited(outputFormat) {
+		records := make([][]string, 0, len(checks))
+		for _, check := range checks {
+			records = append(records, []string{check.Name, check.Description, check.Value, check.CheckType})
+		}
+		writeDelimited(outputFormat, noHeader, kernelCSVHeader, records...)
+	} else if outputFormat == "junit" {
+		printJUnit(newKernelJUnit(results))
 	} else if outputFormat == "xml" {
-		xmlData, err := xml.MarshalIndent(KernelCheck, "", "  ")
+		xmlData, err := xml.MarshalIndent(checks, "", "  ")
 		if err != nil {
-			log.Fatal(err)
+			fmt.Printf("err: %v\n", err)
+			return
 		}
 		fmt.Println(string(xmlData))
 	} else {
@@ -76,14 +74,18 @@ func KernelPrinter(outputFormat string, kernel any, kernelColors any, noBanner b
 				output.ColorPrinter("Config Key", "unset"),
 			)
 		}
-		for _, check := range KernelCheckColor {
+		for _, check := range results {
 			fmt.Printf("%-70s%-26s%-30s%-30s\n",
 				output.ColorPrinter(check.Description, "unset"),
-				output.ColorPrinter(check.Value, check.Color),
-				output.ColorPrinter(check.CheckType, "unset"),
+				output.ColorPrinter(check.Value, check.Color()),
+				output.ColorPrinter(check.Type, "unset"),
 				output.ColorPrinter(check.Name, "unset"),
 			)
 		}
 	}
 
 }
+
+func newKernelCheck(r checksec.KernelResult) KernelCheck {
+	return KernelCheck{Name: r.Name, Description: r.Description, Value: r.Value, CheckType: r.Type}
+}
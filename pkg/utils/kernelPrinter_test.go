@@ -2,33 +2,43 @@ package utils
 
 import (
 	"encoding/json"
+	"maps"
 	"testing"
+
+	"github.com/slimm609/checksec/v3/pkg/checksec"
 )
 
 func TestKernelPrinter_AllFormats(t *testing.T) {
-	k := []interface{}{
-		map[string]any{"name": "CONF", "desc": "d", "value": "Enabled", "type": "Kernel Config"},
+	k := []checksec.KernelResult{
+		{Name: "CONF", Description: "d", Value: "Enabled", Type: "Kernel Config", Status: checksec.StatusPass},
+	}
+
+	out := captureOutput(t, func() { KernelPrinter("json", k, true, true) })
+	var checks []map[string]string
+	if err := json.Unmarshal([]byte(out), &checks); err != nil || len(checks) != 1 {
+		t.Fatalf("expected JSON output, got %q", out)
 	}
-	kc := []interface{}{
-		map[string]any{"name": "CONF", "desc": "d", "value": "Enabled", "type": "Kernel Config", "color": "green"},
+	if want := (map[string]string{"name": "CONF", "desc": "d", "value": "Enabled", "type": "Kernel Config"}); !maps.Equal(checks[0], want) {
+		t.Errorf("JSON check = %v, want %v", checks[0], want)
 	}
 
-	out := captureOutput(t, func() { KernelPrinter("json", k, kc, true, true) })
-	if !json.Valid([]byte(out)) {
-		t.Fatalf("expected JSON output")
+	out = captureOutput(t, func() { KernelPrinter("ndjson", k, true, true) })
+	var line KernelCheck
+	if err := json.Unmarshal([]byte(out), &line); err != nil || line.Name != "CONF" {
+		t.Fatalf("expected one NDJSON line, got %q", out)
 	}
 
-	out = captureOutput(t, func() { KernelPrinter("yaml", k, kc, true, true) })
+	out = captureOutput(t, func() { KernelPrinter("yaml", k, true, true) })
 	if len(out) == 0 {
 		t.Fatalf("expected YAML output")
 	}
 
-	out = captureOutput(t, func() { KernelPrinter("xml", k, kc, true, true) })
+	out = captureOutput(t, func() { KernelPrinter("xml", k, true, true) })
 	if len(out) == 0 || out[0] != '<' {
 		t.Fatalf("expected XML output")
 	}
 
-	out = captureOutput(t, func() { KernelPrinter("table", k, kc, true, false) })
+	out = captureOutput(t, func() { KernelPrinter("table", k, true, false) })
 	if len(out) == 0 {
 		t.Fatalf("expected table output")
 	}
@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"debug/elf"
+	"testing"
+
+	"github.com/slimm609/checksec/v3/pkg/checksec"
+)
+
+// extraCheck is registered for the whole utils test binary so the runner and
+// printers are exercised with a check from outside pkg/checksec.
+type extraCheck struct{}
+
+func (extraCheck) ID() string                         { return "utils_extra" }
+func (extraCheck) Header() string                     { return "Extra" }
+func (extraCheck) Applies(elf.Type, elf.Machine) bool { return true }
+func (extraCheck) Run(b *checksec.Binary) checksec.Result {
+	return checksec.Result{Status: checksec.StatusPass, Output: "extra ok"}
+}
+
+func init() {
+	checksec.Register(extraCheck{})
+}
+
+func TestReportCells_IncludesRegisteredChecks(t *testing.T) {
+	requireFixtureBytes(t)
+
+	var scanner checksec.Scanner
+	report, err := scanner.ScanFile(fixtureELF)
+	if err != nil {
+		t.Fatalf("ScanFile: %v", err)
+	}
+	if report.Name != fixtureELF {
+		t.Errorf("Name = %q, want %q", report.Name, fixtureELF)
+	}
+	if got, ok := report.Result("utils_extra"); !ok || got.Output != "extra ok" {
+		t.Errorf("registered check result = %+v, %v", got, ok)
+	}
+
+	cells := reportCells(report)
+	if last := cells[len(cells)-1]; last.Key != "utils_extra" || last.Text != "extra ok" || last.Color != "green" {
+		t.Errorf("last cell = %+v, want the registered check", last)
+	}
+}
@@ -0,0 +1,167 @@
+package utils
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/slimm609/checksec/v3/pkg/checksec"
+)
+
+// sarifSchema and sarifVersion identify the SARIF 2.1.0 format.
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+// SarifLog is the root of a SARIF 2.1.0 document. Only the properties
+// checksec fills in are modelled.
+type SarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SarifRun `json:"runs"`
+}
+
+// SarifRun is a single run of checksec over one or more binaries.
+type SarifRun struct {
+	Tool    SarifTool     `json:"tool"`
+	Results []SarifResult `json:"results"`
+}
+
+// SarifTool describes checksec and the rules it can report.
+type SarifTool struct {
+	Driver SarifDriver `json:"driver"`
+}
+
+// SarifDriver is the tool component that produced the results.
+type SarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []SarifRule `json:"rules"`
+}
+
+// SarifRule describes one check. Its ID is the check ID, which is stable
+// across releases, and its name the same ID in CamelCase, as SARIF expects
+// of rule names; the column header is the short description.
+type SarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     SarifMessage       `json:"shortDescription"`
+	DefaultConfiguration SarifConfiguration `json:"defaultConfiguration"`
+}
+
+// SarifConfiguration holds the default level of a rule.
+type SarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+// SarifMessage is a plain text message.
+type SarifMessage struct {
+	Text string `json:"text"`
+}
+
+// SarifResult is a mitigation that is missing or only partly in place in a
+// binary.
+type SarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   SarifMessage    `json:"message"`
+	Locations []SarifLocation `json:"locations"`
+}
+
+// SarifLocation points at the scanned binary.
+type SarifLocation struct {
+	PhysicalLocation SarifPhysicalLocation `json:"physicalLocation"`
+}
+
+// SarifPhysicalLocation holds the artifact of a location.
+type SarifPhysicalLocation struct {
+	ArtifactLocation SarifArtifactLocation `json:"artifactLocation"`
+}
+
+// SarifArtifactLocation is the URI of the scanned binary.
+type SarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// sarifLevel maps a check status to a SARIF level. Only failed and partial
+// mitigations become results.
+func sarifLevel(status checksec.Status) (string, bool) {
+	switch status {
+	case checksec.StatusFail:
+		return "error", true
+	case checksec.StatusPartial:
+		return "warning", true
+	}
+	return "", false
+}
+
+// sarifURI returns the artifact URI of a binary path: a file URI for absolute
+// paths and a relative reference otherwise.
+func sarifURI(path string) string {
+	u := url.URL{Path: filepath.ToSlash(path)}
+	if filepath.IsAbs(path) {
+		u.Scheme = "file"
+	}
+	return u.String()
+}
+
+// sarifRuleName returns the CamelCase name of the rule for the check id, for
+// example "CompileFlags" for "compile_flags".
+func sarifRuleName(id string) string {
+	var name strings.Builder
+	for _, word := range strings.Split(id, "_") {
+		if word == "" {
+			continue
+		}
+		name.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return name.String()
+}
+
+// newSarifLog converts reports to a SARIF log with one rule per registered
+// check and one result per failed or partial mitigation.
+func newSarifLog(reports []*checksec.FileReport) SarifLog {
+	checks := checksec.Checks()
+	driver := SarifDriver{
+		Name:           "checksec",
+		InformationURI: "https://github.com/slimm609/checksec",
+		Rules:          make([]SarifRule, 0, len(checks)),
+	}
+	for _, c := range checks {
+		driver.Rules = append(driver.Rules, SarifRule{
+			ID:                   c.ID(),
+			Name:                 sarifRuleName(c.ID()),
+			ShortDescription:     SarifMessage{Text: c.Header()},
+			DefaultConfiguration: SarifConfiguration{Level: "error"},
+		})
+	}
+
+	results := []SarifResult{}
+	for _, report := range reports {
+		location := SarifLocation{PhysicalLocation: SarifPhysicalLocation{
+			ArtifactLocation: SarifArtifactLocation{URI: sarifURI(report.Name)},
+		}}
+		for i, c := range checks {
+			res, _ := report.Result(c.ID())
+			level, ok := sarifLevel(res.Status)
+			if !ok {
+				continue
+			}
+			results = append(results, SarifResult{
+				RuleID:    c.ID(),
+				RuleIndex: i,
+				Level:     level,
+				Message:   SarifMessage{Text: c.Header() + ": " + res.Output},
+				Locations: []SarifLocation{location},
+			})
+		}
+	}
+
+	return SarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []SarifRun{{Tool: SarifTool{Driver: driver}, Results: results}},
+	}
+}
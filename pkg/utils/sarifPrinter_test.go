@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/slimm609/checksec/v3/pkg/checksec"
+)
+
+func TestFilePrinter_SARIF(t *testing.T) {
+	partial := sampleReport()
+	partial.Name = "/usr/bin/my app"
+	partial.Relro = checksec.Result{Status: checksec.StatusPartial, Output: "Partial RELRO"}
+
+	out := captureOutput(t, func() {
+		FilePrinter("sarif", []*checksec.FileReport{sampleReport(), partial}, false, false)
+	})
+	var log SarifLog
+	if err := json.Unmarshal([]byte(out), &log); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, out)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("version %q with %d runs, want 2.1.0 with 1", log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+
+	rules := map[string]int{}
+	for i, rule := range run.Tool.Driver.Rules {
+		rules[rule.ID] = i
+	}
+	for _, id := range []string{"relro", "canary", "cfi", "nx", "pie", "fortify"} {
+		if _, ok := rules[id]; !ok {
+			t.Errorf("no rule %q in %+v", id, run.Tool.Driver.Rules)
+		}
+	}
+	if rule := run.Tool.Driver.Rules[rules["canary"]]; rule.Name != "Canary" || rule.ShortDescription.Text != "Stack Canary" {
+		t.Errorf("canary rule = %+v, want name Canary described as Stack Canary", rule)
+	}
+
+	// Only the failed SafeStack of both reports and the partial RELRO are
+	// results; passing and unknown checks are not.
+	want := []struct{ rule, level, uri, text string }{
+		{"safestack", "error", "bin", "SafeStack: No SafeStack Found"},
+		{"relro", "warning", "file:///usr/bin/my%20app", "RELRO: Partial RELRO"},
+		{"safestack", "error", "file:///usr/bin/my%20app", "SafeStack: No SafeStack Found"},
+	}
+	if len(run.Results) != len(want) {
+		t.Fatalf("got %d results, want %d: %+v", len(run.Results), len(want), run.Results)
+	}
+	for i, w := range want {
+		got := run.Results[i]
+		if got.RuleID != w.rule || got.Level != w.level || got.Message.Text != w.text ||
+			got.Locations[0].PhysicalLocation.ArtifactLocation.URI != w.uri {
+			t.Errorf("results[%d] = %+v, want %+v", i, got, w)
+		}
+		if got.RuleIndex != rules[got.RuleID] {
+			t.Errorf("results[%d].ruleIndex = %d, want %d", i, got.RuleIndex, rules[got.RuleID])
+		}
+	}
+}
+
+func TestSarifRuleName(t *testing.T) {
+	tests := map[string]string{
+		"relro":         "Relro",
+		"nx":            "Nx",
+		"compile_flags": "CompileFlags",
+		"canary":        "Canary",
+	}
+	for id, want := range tests {
+		if got := sarifRuleName(id); got != want {
+			t.Errorf("sarifRuleName(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
+
+func TestFilePrinter_SARIFEmpty(t *testing.T) {
+	out := captureOutput(t, func() { FilePrinter("sarif", nil, false, false) })
+	var log map[string]any
+	if err := json.Unmarshal([]byte(out), &log); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	results := log["runs"].([]any)[0].(map[string]any)["results"]
+	if r, ok := results.([]any); !ok || len(r) != 0 {
+		t.Errorf("results = %#v, want an empty array", results)
+	}
+}
@@ -18,6 +18,8 @@ gcc -o output/none test.c -w -D_FORTIFY_SOURCE=0 -fno-stack-protector -no-pie -O
 gcc -c test.c -o output/rel.o
 # DSO (PIE)
 gcc -shared -fPIC -o output/dso.so test.c -w -D_FORTIFY_SOURCE=2 -fstack-protector-strong -O2 -z relro -z now -z noexecstack -s
+# x86 CET (IBT and SHSTK forced on, as the host crt objects may lack the markers)
+gcc -o output/cet test.c -w -fcf-protection=full -Wl,-z,ibt,-z,shstk
 # CFI and SafeStack
 clang -o output/cfi test.c -w -flto -fsanitize=cfi -fvisibility=default
 clang -o output/sstack test.c -w -fsanitize=safe-stack